@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"accountapi/internal/domain"
 	"accountapi/internal/entrypoint/rest"
+	"accountapi/internal/infrastructure/moderation"
 )
 
 func main() {
@@ -16,14 +25,328 @@ func main() {
 		port = "8080"
 	}
 
-	handler := rest.New()
+	cfg := loadConfig()
+	tlsCfg, err := loadTLSConfig()
+	if err != nil {
+		log.Fatalf("tls config: %v", err)
+	}
+	if tlsCfg != nil && tlsCfg.ClientCAs != nil {
+		users, err := loadCertUserMap()
+		if err != nil {
+			log.Fatalf("client certificate mapping: %v", err)
+		}
+		cfg.CertUsers = users
+	}
+	schema, err := loadProfileSchema()
+	if err != nil {
+		log.Fatalf("profile schema: %v", err)
+	}
+	cfg.ProfileSchema = schema
+	words, err := loadModerationWords()
+	if err != nil {
+		log.Fatalf("moderation words: %v", err)
+	}
+	if words != nil {
+		cfg.Moderator = words
+	}
+	if err := loadTenants(&cfg); err != nil {
+		log.Fatalf("tenants: %v", err)
+	}
+
+	admins, err := loadAdminAccounts()
+	if err != nil {
+		log.Fatalf("admin accounts: %v", err)
+	}
+	cfg.AdminAccounts = admins
+
+	handler := rest.New(cfg)
+	if err := handler.ProvisionAdmins(); err != nil {
+		log.Fatalf("admin accounts: %v", err)
+	}
+	sweepInterval := time.Hour
+	if v, ok := lookupDuration("EXPORT_SWEEP_INTERVAL"); ok && v > 0 {
+		sweepInterval = v
+	}
+	go handler.RunExportSweeper(context.Background(), sweepInterval)
 	srv := &http.Server{
 		Addr:         ":" + port,
 		Handler:      handler,
+		TLSConfig:    tlsCfg,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	if tlsCfg != nil {
+		log.Printf("listening on :%s (TLS, client auth: %s)", port, tlsCfg.ClientAuth)
+		log.Fatal(srv.ListenAndServeTLS("", ""))
+	}
 	log.Printf("listening on :%s", port)
 	log.Fatal(srv.ListenAndServe())
 }
+
+// loadTLSConfig: TLS_CERT_FILE/TLS_KEY_FILE があれば TLS で待ち受ける。
+// TLS_CLIENT_CA_FILE を指定するとクライアント証明書を検証する（TLS_CLIENT_AUTH=require で必須）
+func loadTLSConfig() (*tls.Config, error) {
+	certFile, hasCert := lookupEnv("TLS_CERT_FILE")
+	keyFile, hasKey := lookupEnv("TLS_KEY_FILE")
+	if !hasCert && !hasKey {
+		return nil, nil
+	}
+	if !hasCert || !hasKey {
+		return nil, errors.New("both TLS_CERT_FILE and TLS_KEY_FILE are required")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	caFile, ok := lookupEnv("TLS_CLIENT_CA_FILE")
+	if !ok {
+		return cfg, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if mode, ok := lookupEnv("TLS_CLIENT_AUTH"); ok {
+		switch strings.ToLower(mode) {
+		case "optional":
+		case "require":
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		default:
+			return nil, fmt.Errorf("invalid TLS_CLIENT_AUTH=%q (optional or require)", mode)
+		}
+	}
+	return cfg, nil
+}
+
+// loadCertUserMap: CLIENT_CERT_USER_MAP_FILE（JSON）から証明書と user_id の対応表を読み込む
+func loadCertUserMap() (rest.CertUserMap, error) {
+	path, ok := lookupEnv("CLIENT_CERT_USER_MAP_FILE")
+	if !ok {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return rest.ParseCertUserMap(data)
+}
+
+// loadAdminAccounts: ADMIN_ACCOUNTS_FILE（JSON）から管理者のアカウント（user_id → パスワードハッシュ）を読み込む
+func loadAdminAccounts() (map[string]string, error) {
+	path, ok := lookupEnv("ADMIN_ACCOUNTS_FILE")
+	if !ok {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return rest.ParseAdminAccounts(data)
+}
+
+// loadProfileSchema: PROFILE_SCHEMA_FILE（JSON）からカスタムプロフィール項目の定義を読み込む
+func loadProfileSchema() (*domain.ProfileSchema, error) {
+	path, ok := lookupEnv("PROFILE_SCHEMA_FILE")
+	if !ok {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ParseProfileSchema(data)
+}
+
+// loadTenants: TENANT_RESOLUTION を指定するとテナントごとに名前空間を分ける。
+// テナントは TENANTS_FILE（JSON）に定義したものだけを受け付ける
+func loadTenants(cfg *rest.Config) error {
+	v, ok := lookupEnv("TENANT_RESOLUTION")
+	if !ok {
+		return nil
+	}
+	mode, err := rest.ParseTenantResolution(v)
+	if err != nil || mode == rest.TenantNone {
+		return err
+	}
+	path, ok := lookupEnv("TENANTS_FILE")
+	if !ok {
+		return errors.New("TENANTS_FILE is required with TENANT_RESOLUTION")
+	}
+	// 証明書の対応表はテナントを区別しないため併用できない
+	if len(cfg.CertUsers) > 0 {
+		return errors.New("CLIENT_CERT_USER_MAP_FILE cannot be used with TENANT_RESOLUTION")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	tenants, err := rest.ParseTenantPolicies(data)
+	if err != nil {
+		return err
+	}
+	cfg.TenantResolution, cfg.Tenants = mode, tenants
+	return nil
+}
+
+// loadModerationWords: MODERATION_WORDS_FILE（1 行 1 語）から禁止語の一覧を読み込む。
+// 行頭の reject:・mask:・hold: で処置を指定でき、省略時は MODERATION_DEFAULT_ACTION（既定 reject）
+func loadModerationWords() (*moderation.WordList, error) {
+	path, ok := lookupEnv("MODERATION_WORDS_FILE")
+	if !ok {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	action := domain.ModerationReject
+	if v, ok := lookupEnv("MODERATION_DEFAULT_ACTION"); ok {
+		action = domain.ModerationAction(strings.ToLower(v))
+	}
+	return moderation.ParseWordList(data, action)
+}
+
+func loadConfig() rest.Config {
+	var cfg rest.Config
+	if v, ok := lookupBool("SIGNUP_CONCEAL_EXISTING"); ok {
+		cfg.ConcealExistingUsers = v
+	}
+	if v, ok := lookupInt("HASH_CONCURRENCY"); ok {
+		cfg.HashConcurrency = v
+	}
+	if v, ok := lookupInt("HASH_QUEUE_DEPTH"); ok {
+		cfg.HashQueueDepth = v
+	}
+	if v, ok := lookupDuration("HASH_WAIT_TIMEOUT"); ok {
+		cfg.HashWaitTimeout = v
+	}
+	if v, ok := lookupInt("IMAGE_CONCURRENCY"); ok {
+		cfg.ImageConcurrency = v
+	}
+	if v, ok := lookupDuration("SESSION_TTL"); ok {
+		cfg.SessionTTL = v
+	}
+	if v, ok := lookupBool("TRUST_PROXY_HEADERS"); ok {
+		cfg.TrustProxyHeaders = v
+	}
+	if v, ok := lookupEnv("MAIL_DIR"); ok {
+		cfg.MailDir = v
+	}
+	if v, ok := lookupDuration("EMAIL_VERIFICATION_TTL"); ok {
+		cfg.EmailVerificationTTL = v
+	}
+	if v, ok := lookupDuration("EMAIL_RESEND_INTERVAL"); ok {
+		cfg.EmailResendInterval = v
+	}
+	cfg.ActivityMaxEvents = 1000
+	if v, ok := lookupInt("ACTIVITY_MAX_EVENTS"); ok {
+		cfg.ActivityMaxEvents = v
+	}
+	cfg.ActivityRetention = 90 * 24 * time.Hour
+	if v, ok := lookupDuration("ACTIVITY_RETENTION"); ok {
+		cfg.ActivityRetention = v
+	}
+	if v, ok := lookupList("ADMIN_USER_IDS"); ok {
+		cfg.Admins = v
+	}
+	if v, ok := lookupDuration("IMPERSONATION_TTL"); ok {
+		cfg.ImpersonationTTL = v
+	}
+	if v, ok := lookupDuration("USER_ID_ALIAS_TTL"); ok {
+		cfg.UserIDAliasTTL = v
+	}
+	if v, ok := lookupDuration("EXPORT_TTL"); ok {
+		cfg.ExportTTL = v
+	}
+	if v, ok := lookupBool("NICKNAME_REJECT_CONFUSABLE"); ok {
+		cfg.RejectConfusableNicknames = v
+	}
+	if v, ok := lookupBool("NICKNAME_UNIQUE"); ok {
+		cfg.UniqueNicknames = v
+	}
+	if v, ok := lookupList("NICKNAME_RESERVED"); ok {
+		cfg.ReservedNicknames = v
+	}
+	cfg.BlobDir = filepath.Join(os.TempDir(), "accountapi-blobs")
+	if v, ok := lookupEnv("BLOB_DIR"); ok {
+		cfg.BlobDir = v
+	}
+	return cfg
+}
+
+func lookupEnv(key string) (string, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return "", false
+	}
+	trimmed := strings.TrimSpace(v)
+	if trimmed == "" {
+		return "", false
+	}
+	return trimmed, true
+}
+
+func lookupBool(key string) (bool, bool) {
+	v, ok := lookupEnv(key)
+	if !ok {
+		return false, false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q", key, v)
+		return false, false
+	}
+	return b, true
+}
+
+func lookupInt(key string) (int, bool) {
+	v, ok := lookupEnv(key)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q", key, v)
+		return 0, false
+	}
+	return n, true
+}
+
+func lookupDuration(key string) (time.Duration, bool) {
+	v, ok := lookupEnv(key)
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("ignoring invalid %s=%q", key, v)
+		return 0, false
+	}
+	return d, true
+}
+
+// lookupList: カンマ区切りの値（空要素は除く）
+func lookupList(key string) ([]string, bool) {
+	v, ok := lookupEnv(key)
+	if !ok {
+		return nil, false
+	}
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out, len(out) > 0
+}
@@ -2,4 +2,8 @@ module accountapi
 
 go 1.25
 
-require golang.org/x/crypto v0.26.0
+require (
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/crypto v0.26.0
+	golang.org/x/text v0.17.0
+)
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	_ "image/gif" // デコーダ登録
+	_ "image/jpeg"
+	"image/png"
+)
+
+// アバター画像の制限
+const (
+	MaxAvatarBytes     = 5 << 20 // アップロードできる最大サイズ
+	MaxAvatarDimension = 4096    // 縦横それぞれの最大ピクセル数
+	MaxAvatarPixels    = 8 << 20 // 縦×横の上限（展開後は 1 ピクセル 4 バイトのため、1 枚あたり 32 MiB 程度に抑える）
+	avatarMaxEdge      = 1024    // 保存する元画像の長辺の上限
+)
+
+// AvatarThumbnailSizes: 生成する正方形サムネイルの一辺（px）
+var AvatarThumbnailSizes = []int{256, 128, 64}
+
+// AvatarImages: 再エンコード済みの PNG。Thumbnails のキーは一辺のピクセル数
+type AvatarImages struct {
+	Original   []byte
+	Thumbnails map[int][]byte
+}
+
+// ProcessAvatar: PNG/JPEG/GIF をデコードして PNG に再エンコードし（メタデータは残らない）、サムネイルを生成する
+func ProcessAvatar(data []byte) (*AvatarImages, error) {
+	if len(data) == 0 {
+		return nil, &ErrValidation{Reason: ValidationReasonAvatarFormat}
+	}
+	if len(data) > MaxAvatarBytes {
+		return nil, &ErrValidation{Reason: ValidationReasonAvatarTooLarge}
+	}
+	// 展開前に寸法を確認する（巨大画像によるメモリ消費を防ぐ）
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg" && format != "gif") {
+		return nil, &ErrValidation{Reason: ValidationReasonAvatarFormat}
+	}
+	if cfg.Width < 1 || cfg.Height < 1 || cfg.Width > MaxAvatarDimension || cfg.Height > MaxAvatarDimension ||
+		cfg.Width*cfg.Height > MaxAvatarPixels {
+		return nil, &ErrValidation{Reason: ValidationReasonAvatarDimensions}
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ErrValidation{Reason: ValidationReasonAvatarFormat}
+	}
+	rgba := toRGBA(src)
+
+	// 元画像は長辺を avatarMaxEdge までに縮小して保存する
+	w, h := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+	if w > avatarMaxEdge || h > avatarMaxEdge {
+		if w >= h {
+			w, h = avatarMaxEdge, max(1, h*avatarMaxEdge/w)
+		} else {
+			w, h = max(1, w*avatarMaxEdge/h), avatarMaxEdge
+		}
+		rgba = resizeArea(rgba, w, h)
+	}
+	out := &AvatarImages{Thumbnails: make(map[int][]byte, len(AvatarThumbnailSizes))}
+	if out.Original, err = encodePNG(rgba); err != nil {
+		return nil, err
+	}
+	square := cropSquare(rgba)
+	for _, size := range AvatarThumbnailSizes {
+		// 元画像より大きくはしない
+		side := min(size, square.Bounds().Dx())
+		b, err := encodePNG(resizeArea(square, side, side))
+		if err != nil {
+			return nil, err
+		}
+		out.Thumbnails[size] = b
+	}
+	return out, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		// 変換が不要なら複製しない
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// cropSquare: 中央を正方形に切り出す
+func cropSquare(src *image.RGBA) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	side := min(w, h)
+	x0, y0 := (w-side)/2, (h-side)/2
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), src, image.Pt(x0, y0), draw.Src)
+	return dst
+}
+
+// resizeArea: 面積平均による縮小（拡大時は最近傍）。乗算済みアルファのまま平均する
+func resizeArea(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	if sw == dw && sh == dh {
+		copy(dst.Pix, src.Pix)
+		return dst
+	}
+	for y := 0; y < dh; y++ {
+		y0 := y * sh / dh
+		y1 := max((y+1)*sh/dh, y0+1)
+		for x := 0; x < dw; x++ {
+			x0 := x * sw / dw
+			x1 := max((x+1)*sw/dw, x0+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			j := y*dst.Stride + x*4
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := enc.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
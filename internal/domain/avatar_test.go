@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// pngHeader: 寸法の確認だけに使う、IHDR までの PNG
+func pngHeader(w, h int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(w))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(h))
+	ihdr[8], ihdr[9] = 8, 6 // 8 bit RGBA
+	chunk := append([]byte("IHDR"), ihdr...)
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestProcessAvatarRejectsLargeImagesBeforeDecoding(t *testing.T) {
+	for _, size := range [][2]int{{4096, 4096}, {3000, 3000}, {4097, 1}, {1, 4097}} {
+		_, err := ProcessAvatar(pngHeader(size[0], size[1]))
+		var vErr *ErrValidation
+		if !errors.As(err, &vErr) || vErr.Reason != ValidationReasonAvatarDimensions {
+			t.Errorf("%dx%d: err = %v, want avatar dimensions error", size[0], size[1], err)
+		}
+	}
+	// 上限内なら寸法では拒否しない（本文が無いためデコードで失敗する）
+	_, err := ProcessAvatar(pngHeader(4096, 2048))
+	var vErr *ErrValidation
+	if !errors.As(err, &vErr) || vErr.Reason != ValidationReasonAvatarFormat {
+		t.Errorf("4096x2048: err = %v, want avatar format error", err)
+	}
+}
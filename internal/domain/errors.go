@@ -8,10 +8,90 @@ const (
 	ValidationReasonInvalidPattern     ValidationReason = "invalid_pattern"
 	ValidationReasonProfileRequired    ValidationReason = "profile_required"
 	ValidationReasonProfileConstraint  ValidationReason = "profile_constraint"
+	// カスタムプロフィール項目
+	ValidationReasonProfileAttributeUnknown  ValidationReason = "profile_attribute_unknown"
+	ValidationReasonProfileAttributeInvalid  ValidationReason = "profile_attribute_invalid"
+	ValidationReasonProfileAttributeRequired ValidationReason = "profile_attribute_required"
+	ValidationReasonPrivacyInvalid           ValidationReason = "privacy_invalid"
+	ValidationReasonEmailInvalid             ValidationReason = "email_invalid"
+	ValidationReasonAvatarTooLarge           ValidationReason = "avatar_too_large"
+	ValidationReasonAvatarFormat             ValidationReason = "avatar_format"
+	ValidationReasonAvatarDimensions         ValidationReason = "avatar_dimensions"
+	ValidationReasonGroupName                ValidationReason = "group_name"
 )
 
+// FieldErrorCode: 項目ごとの検証エラーの種類（機械可読）
+type FieldErrorCode string
+
+const (
+	FieldRequired          FieldErrorCode = "required"
+	FieldTooShort          FieldErrorCode = "too_short"          // params: min
+	FieldTooLong           FieldErrorCode = "too_long"           // params: max
+	FieldInvalidPattern    FieldErrorCode = "invalid_pattern"    // params: pattern
+	FieldInvalidCharacters FieldErrorCode = "invalid_characters" // 制御コード・ゼロ幅文字など
+	FieldInvalidFormat     FieldErrorCode = "invalid_format"     // params: format（url・date・integer）
+	FieldUnknown           FieldErrorCode = "unknown"            // スキーマに無いカスタム項目
+)
+
+// FieldError: 1 つの項目の検証エラー。Params は制約の値（max: 30 など）
+type FieldError struct {
+	Field  string
+	Code   FieldErrorCode
+	Params map[string]any
+}
+
 type ErrValidation struct {
 	Reason ValidationReason
+	// Fields: 失敗したすべての項目（Reason は従来どおり最初の失敗の理由）
+	Fields []FieldError
 }
 
 func (e *ErrValidation) Error() string { return string(e.Reason) }
+
+// validationErrors: 検証エラーを集める。Reason には最初に追加した失敗の理由を使う
+type validationErrors struct {
+	reason ValidationReason
+	fields []FieldError
+}
+
+func (v *validationErrors) add(reason ValidationReason, fields ...FieldError) {
+	if v.reason == "" {
+		v.reason = reason
+	}
+	v.fields = append(v.fields, fields...)
+}
+
+// merge: err が ErrValidation ならその理由と項目を加える
+func (v *validationErrors) merge(err error) {
+	if e, ok := err.(*ErrValidation); ok {
+		v.add(e.Reason, e.Fields...)
+	}
+}
+
+// has: field の失敗を既に含むか
+func (v *validationErrors) has(field string) bool {
+	for _, f := range v.fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validationErrors) err() error {
+	if v.reason == "" {
+		return nil
+	}
+	return &ErrValidation{Reason: v.reason, Fields: v.fields}
+}
+
+// lengthError: 長さ l が min..max の範囲外なら too_short・too_long
+func lengthError(field string, l, min, max int) (FieldError, bool) {
+	switch {
+	case l < min:
+		return FieldError{Field: field, Code: FieldTooShort, Params: map[string]any{"min": min}}, true
+	case l > max:
+		return FieldError{Field: field, Code: FieldTooLong, Params: map[string]any{"max": max}}, true
+	}
+	return FieldError{}, false
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// ExportStatus: 個人データの書き出しの状態
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending" // 作成中
+	ExportReady   ExportStatus = "ready"   // ダウンロードできる（ExpiresAt まで）
+	ExportFailed  ExportStatus = "failed"
+	// ExportExpired: ダウンロードの期限切れ。保存はせず、参照時に ExportReady から置き換える
+	ExportExpired ExportStatus = "expired"
+)
+
+// ExportRecord: 個人データの書き出し要求。アーカイブ本体は BlobStore に置く
+type ExportRecord struct {
+	ID          string
+	UserID      string
+	Status      ExportStatus
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	// ExpiresAt: ダウンロードの期限（ExportReady のみ）
+	ExpiresAt time.Time
+	Size      int
+}
+
+// Expired: at の時点でダウンロードの期限を過ぎているか
+func (r *ExportRecord) Expired(at time.Time) bool {
+	return r.Status == ExportReady && !at.Before(r.ExpiresAt)
+}
+
+type ExportRepository interface {
+	Create(rec *ExportRecord) error
+	// Update: 状態を書き換える。存在しなければ ErrNotFound
+	Update(rec *ExportRecord) error
+	// Find: userID のものでなければ ErrNotFound
+	Find(userID, id string) (*ExportRecord, error)
+	// FindByID: ユーザーを問わず ID で探す。存在しなければ ErrNotFound
+	FindByID(id string) (*ExportRecord, error)
+	// ListByUser: 古い順
+	ListByUser(userID string) ([]*ExportRecord, error)
+	Delete(id string) error
+	DeleteByUser(userID string) error
+	RenameUser(oldID, newID string) error
+}
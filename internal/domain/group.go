@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// GroupRole: グループでの役割
+type GroupRole string
+
+const (
+	// GroupOwner: グループ名の変更・削除とメンバーの管理ができる
+	GroupOwner  GroupRole = "owner"
+	GroupMember GroupRole = "member"
+)
+
+func (r GroupRole) Valid() bool {
+	return r == GroupOwner || r == GroupMember
+}
+
+// GroupRecord: ユーザーをまとめるグループ（チーム）
+type GroupRecord struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// GroupMembership: UserID が GroupID のメンバーである
+type GroupMembership struct {
+	GroupID string
+	UserID  string
+	Role    GroupRole
+	// JoinedAt: 参加した日時（招待中は招待された日時）
+	JoinedAt time.Time
+	// Pending: 招待中。本人が承諾するまでメンバーとして扱わない
+	Pending bool
+}
+
+// NormalizeGroupName: 前後の空白を除いて NFC 正規化し、1..50 文字（制御コード禁止）か検証する
+func NormalizeGroupName(name string) (string, error) {
+	name = norm.NFC.String(strings.TrimSpace(name))
+	if !withinLen(name, 1, 50) || hasControl(name) {
+		return "", &ErrValidation{Reason: ValidationReasonGroupName}
+	}
+	return name, nil
+}
+
+type GroupRepository interface {
+	// Create: グループを作成し、owner を最初のメンバーとして追加する
+	Create(g *GroupRecord, owner *GroupMembership) error
+	// Find: 存在しなければ ErrNotFound
+	Find(id string) (*GroupRecord, error)
+	Rename(id, name string, at time.Time) error
+	// Delete: メンバーシップもすべて削除する
+	Delete(id string) error
+	// AddMember: 既にメンバー（招待中を含む）なら ErrAlreadyExists
+	AddMember(m *GroupMembership) error
+	// Accept: 招待中のメンバーシップを参加にする。メンバーでなければ ErrNotFound
+	Accept(groupID, userID string, at time.Time) error
+	// SetRole・RemoveMember・Membership: メンバーでなければ ErrNotFound
+	SetRole(groupID, userID string, role GroupRole) error
+	RemoveMember(groupID, userID string) error
+	Membership(groupID, userID string) (*GroupMembership, error)
+	// ListMembers: 参加の古い順
+	ListMembers(groupID string) ([]*GroupMembership, error)
+	// ListByUser: userID のメンバーシップ（参加の古い順）
+	ListByUser(userID string) ([]*GroupMembership, error)
+	RenameUser(oldID, newID string) error
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// identicon のサイズ（px）の範囲と既定値
+const (
+	IdenticonMinSize     = 16
+	IdenticonMaxSize     = 1024
+	IdenticonDefaultSize = 128
+)
+
+// identicon のグリッド（左右対称の 5x5）と、外周の余白（セル単位）
+const (
+	identiconGrid   = 5
+	identiconMargin = 0.5
+)
+
+var identiconBackground = color.RGBA{0xF0, 0xF0, 0xF0, 0xFF}
+
+// Identicon: user_id から決まるパターンと色
+type Identicon struct {
+	cells [identiconGrid][identiconGrid]bool
+	color color.RGBA
+}
+
+// NewIdenticon: user_id の SHA-256 から左右対称のパターンと色を決める（同じ user_id なら常に同じ）
+func NewIdenticon(userID string) *Identicon {
+	sum := sha256.Sum256([]byte(userID))
+	ic := &Identicon{}
+	// 左半分＋中央列（3 列 x 5 行 = 15 ビット）を決めて右側に写す
+	bit := 0
+	half := (identiconGrid + 1) / 2
+	for x := 0; x < half; x++ {
+		for y := 0; y < identiconGrid; y++ {
+			on := sum[bit/8]>>(bit%8)&1 == 1
+			ic.cells[y][x] = on
+			ic.cells[y][identiconGrid-1-x] = on
+			bit++
+		}
+	}
+	hue := float64(uint16(sum[16])<<8|uint16(sum[17])) / 65536 * 360
+	sat := 0.45 + float64(sum[18])/255*0.2
+	light := 0.45 + float64(sum[19])/255*0.15
+	ic.color = hslToRGB(hue, sat, light)
+	return ic
+}
+
+// Image: size x size の画像を描画する
+func (ic *Identicon) Image(size int) image.Image {
+	pal := color.Palette{identiconBackground, ic.color}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), pal)
+	cell := float64(size) / (identiconGrid + 2*identiconMargin)
+	// 画素の中心がどのセルに入るかで塗る
+	for py := 0; py < size; py++ {
+		fy := (float64(py)+0.5)/cell - identiconMargin
+		if fy < 0 || fy >= identiconGrid {
+			continue
+		}
+		for px := 0; px < size; px++ {
+			fx := (float64(px)+0.5)/cell - identiconMargin
+			if fx < 0 || fx >= identiconGrid {
+				continue
+			}
+			if ic.cells[int(fy)][int(fx)] {
+				img.SetColorIndex(px, py, 1)
+			}
+		}
+	}
+	return img
+}
+
+// PNG: size x size の PNG
+func (ic *Identicon) PNG(size int) ([]byte, error) {
+	return encodePNG(ic.Image(size))
+}
+
+// SVG: size x size の SVG（セル単位の viewBox で拡大縮小に強い）
+func (ic *Identicon) SVG(size int) []byte {
+	view := identiconGrid + 2*identiconMargin
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %g %g" shape-rendering="crispEdges">`, size, size, view, view)
+	fmt.Fprintf(&b, `<rect width="%g" height="%g" fill="%s"/>`, view, view, hexColor(identiconBackground))
+	fmt.Fprintf(&b, `<g fill="%s">`, hexColor(ic.color))
+	for y := 0; y < identiconGrid; y++ {
+		for x := 0; x < identiconGrid; x++ {
+			if ic.cells[y][x] {
+				fmt.Fprintf(&b, `<rect x="%g" y="%g" width="1" height="1"/>`, float64(x)+identiconMargin, float64(y)+identiconMargin)
+			}
+		}
+	}
+	b.WriteString(`</g></svg>`)
+	return []byte(b.String())
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+func hslToRGB(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g, b = c, x, 0
+	case hp < 2:
+		r, g, b = x, c, 0
+	case hp < 3:
+		r, g, b = 0, c, x
+	case hp < 4:
+		r, g, b = 0, x, c
+	case hp < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := l - c/2
+	return color.RGBA{uint8((r + m) * 255), uint8((g + m) * 255), uint8((b + m) * 255), 0xFF}
+}
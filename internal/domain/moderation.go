@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// ModerationAction: 投稿内容の審査結果に応じた処置
+type ModerationAction string
+
+const (
+	ModerationAllow  ModerationAction = "allow"
+	ModerationMask   ModerationAction = "mask"   // 該当部分を伏せ字にして受け付ける
+	ModerationHold   ModerationAction = "hold"   // 管理者の承認まで反映しない
+	ModerationReject ModerationAction = "reject" // 受け付けない
+)
+
+// Severity: 複数の処置が該当した場合は大きいものを採用する
+func (a ModerationAction) Severity() int {
+	switch a {
+	case ModerationMask:
+		return 1
+	case ModerationHold:
+		return 2
+	case ModerationReject:
+		return 3
+	}
+	return 0
+}
+
+// ModerationVerdict: 1 つの文字列に対する審査結果
+type ModerationVerdict struct {
+	Action ModerationAction
+	// Text: ModerationMask の場合の伏せ字にした文字列
+	Text string
+	// Matches: 該当した語
+	Matches []string
+}
+
+// ModerationRecord: 審査待ちのプロフィール変更（ユーザーごとに最新の 1 件）
+type ModerationRecord struct {
+	ID        string
+	UserID    string
+	Update    ProfileUpdate
+	Matches   []string
+	CreatedAt time.Time
+}
+
+type ModerationRepository interface {
+	// Put: 審査待ちに追加する。同じユーザーの審査待ちがあれば置き換える
+	Put(rec *ModerationRecord) error
+	// List: 古い順に最大 limit 件
+	List(limit int) ([]*ModerationRecord, error)
+	Find(id string) (*ModerationRecord, error)
+	Delete(id string) error
+	DeleteByUser(userID string) error
+	RenameUser(oldID, newID string) error
+}
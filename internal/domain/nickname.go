@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeNickname: NFC に正規化する。保存・比較はすべて正規化後の値で行う
+func NormalizeNickname(s string) string {
+	return norm.NFC.String(s)
+}
+
+// hasFormatChar: ゼロ幅文字・双方向制御（bidi override など）を含むか
+func hasFormatChar(s string) bool {
+	for _, r := range s {
+		// Cf: U+200B..U+200F, U+202A..U+202E, U+2066..U+2069, U+FEFF など
+		if unicode.Is(unicode.Cf, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// NicknameSkeleton: 見た目が紛らわしい表示名を同一視するための比較キー（UTS #39 の skeleton を簡略化したもの）。
+// 互換分解して結合文字を除き、ラテン文字と紛らわしいキリル・ギリシャ文字や数字を置き換えて小文字にする。
+// 大文字小文字も同一視するため、本来の skeleton より広く一致する
+func NicknameSkeleton(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if c, ok := confusables[r]; ok {
+			r = c
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	// "rn" と "m" のように複数文字で紛らわしいもの
+	return strings.ReplaceAll(b.String(), "rn", "m")
+}
+
+// confusables: Unicode confusables.txt のうち、ラテン文字と取り違えやすいものの抜粋
+var confusables = map[rune]rune{
+	// 大文字小文字を同一視するため I・i・l・1 はすべて l に寄せる
+	'0': 'o', '1': 'l', 'I': 'l', 'i': 'l', '|': 'l',
+	// キリル文字
+	'А': 'a', 'В': 'b', 'Е': 'e', 'К': 'k', 'М': 'm', 'Н': 'h', 'О': 'o', 'Р': 'p',
+	'С': 'c', 'Т': 't', 'У': 'y', 'Х': 'x', 'Ѕ': 's', 'І': 'l', 'Ј': 'j', 'Ԁ': 'd',
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	'і': 'l', 'ј': 'j', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h', 'ӏ': 'l', 'ү': 'y',
+	// ギリシャ文字
+	'Α': 'a', 'Β': 'b', 'Ε': 'e', 'Ζ': 'z', 'Η': 'h', 'Ι': 'l', 'Κ': 'k', 'Μ': 'm',
+	'Ν': 'n', 'Ο': 'o', 'Ρ': 'p', 'Τ': 't', 'Υ': 'y', 'Χ': 'x',
+	'ο': 'o', 'ν': 'v', 'ρ': 'p', 'ι': 'l',
+}
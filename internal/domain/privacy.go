@@ -0,0 +1,90 @@
+package domain
+
+// PrivacySettings: ユーザーごとのプロフィールの公開範囲
+type PrivacySettings struct {
+	// Profile: プロフィール全体の公開範囲（空文字は public）。private の場合、他ユーザーには存在しないものとして扱う
+	Profile Visibility
+	// Fields: 項目ごとの公開範囲（nickname・comment・カスタム項目名）。Profile より広くはならない
+	Fields map[string]Visibility
+}
+
+const (
+	PrivacyFieldNickname = "nickname"
+	PrivacyFieldComment  = "comment"
+)
+
+// rank: 公開範囲の広さ（大きいほど狭い）
+func (v Visibility) rank() int {
+	switch v {
+	case VisibilityAuthenticated:
+		return 1
+	case VisibilityGroups:
+		return 2
+	case VisibilityPrivate:
+		return 3
+	}
+	return 0
+}
+
+// Narrower: 2 つの公開範囲のうち狭い方
+func (v Visibility) Narrower(other Visibility) Visibility {
+	if other.rank() > v.rank() {
+		return other
+	}
+	if v == "" {
+		return VisibilityPublic
+	}
+	return v
+}
+
+// NewPrivacySettings: 入力を検証して公開範囲の設定を作る。schema にない項目名は受け付けない
+func NewPrivacySettings(profile Visibility, fields map[string]Visibility, schema *ProfileSchema) (PrivacySettings, error) {
+	if profile == "" {
+		profile = VisibilityPublic
+	}
+	if !profile.valid() {
+		return PrivacySettings{}, &ErrValidation{Reason: ValidationReasonPrivacyInvalid}
+	}
+	p := PrivacySettings{Profile: profile}
+	for name, v := range fields {
+		known := name == PrivacyFieldNickname || name == PrivacyFieldComment || schema.Field(name) != nil
+		if !known || !v.valid() {
+			return PrivacySettings{}, &ErrValidation{Reason: ValidationReasonPrivacyInvalid}
+		}
+		if p.Fields == nil {
+			p.Fields = make(map[string]Visibility, len(fields))
+		}
+		p.Fields[name] = v
+	}
+	return p, nil
+}
+
+// ProfileVisibility: プロフィール全体の公開範囲
+func (p PrivacySettings) ProfileVisibility() Visibility {
+	return p.Profile.Narrower(VisibilityPublic)
+}
+
+// FieldVisibility: 項目の実際の公開範囲（全体・項目・既定値 base のうち最も狭いもの）
+func (p PrivacySettings) FieldVisibility(name string, base Visibility) Visibility {
+	return p.ProfileVisibility().Narrower(p.Fields[name]).Narrower(base)
+}
+
+// Viewer: 本人以外の閲覧者
+type Viewer struct {
+	Authenticated bool
+	// SharesGroup: 閲覧者と対象のユーザーが同じグループのメンバー
+	SharesGroup bool
+}
+
+// VisibleTo: 本人以外の閲覧者に見せてよいか
+func (v Visibility) VisibleTo(viewer Viewer) bool {
+	switch v.Narrower(VisibilityPublic) {
+	case VisibilityPrivate:
+		return false
+	case VisibilityGroups:
+		return viewer.SharesGroup
+	case VisibilityAuthenticated:
+		return viewer.Authenticated
+	}
+	return true
+}
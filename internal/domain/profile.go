@@ -0,0 +1,232 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Visibility: プロフィール項目を閲覧できる範囲
+type Visibility string
+
+const (
+	VisibilityPublic        Visibility = "public"        // 誰でも
+	VisibilityAuthenticated Visibility = "authenticated" // 認証済みのユーザー
+	VisibilityGroups        Visibility = "groups"        // 同じグループのメンバー
+	VisibilityPrivate       Visibility = "private"       // 本人のみ
+)
+
+func (v Visibility) valid() bool {
+	return v == VisibilityPublic || v == VisibilityAuthenticated || v == VisibilityGroups || v == VisibilityPrivate
+}
+
+// ProfileFieldType: カスタム項目の値の型
+type ProfileFieldType string
+
+const (
+	ProfileFieldString  ProfileFieldType = "string"
+	ProfileFieldURL     ProfileFieldType = "url"     // http/https の絶対 URL
+	ProfileFieldDate    ProfileFieldType = "date"    // YYYY-MM-DD
+	ProfileFieldInteger ProfileFieldType = "integer" // 10 進整数
+)
+
+// ProfileField: カスタムプロフィール項目の定義
+type ProfileField struct {
+	Name       string
+	Type       ProfileFieldType
+	MaxLength  int            // 文字数の上限（0 は既定値 200）
+	Pattern    *regexp.Regexp // 値全体が一致すること（nil は制約なし）
+	Required   bool           // 更新で空にできない（更新に含まれない場合は確認しない）
+	Visibility Visibility
+}
+
+const defaultProfileFieldMaxLength = 200
+
+// ProfileSchema: 設定で追加するカスタムプロフィール項目
+type ProfileSchema struct {
+	Fields []ProfileField
+	byName map[string]*ProfileField
+}
+
+var reFieldName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// ParseProfileSchema: JSON のスキーマ定義を読み込む
+//
+//	{"fields": [{"name": "website", "type": "url", "max_length": 200, "pattern": "", "required": false, "visibility": "public"}]}
+func ParseProfileSchema(data []byte) (*ProfileSchema, error) {
+	var raw struct {
+		Fields []struct {
+			Name       string `json:"name"`
+			Type       string `json:"type"`
+			MaxLength  int    `json:"max_length"`
+			Pattern    string `json:"pattern"`
+			Required   bool   `json:"required"`
+			Visibility string `json:"visibility"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	fields := make([]ProfileField, 0, len(raw.Fields))
+	for _, f := range raw.Fields {
+		if !reFieldName.MatchString(f.Name) {
+			return nil, fmt.Errorf("profile field %q: name must match %s", f.Name, reFieldName)
+		}
+		field := ProfileField{
+			Name:       f.Name,
+			Type:       ProfileFieldType(f.Type),
+			MaxLength:  f.MaxLength,
+			Required:   f.Required,
+			Visibility: Visibility(f.Visibility),
+		}
+		if field.Type == "" {
+			field.Type = ProfileFieldString
+		}
+		switch field.Type {
+		case ProfileFieldString, ProfileFieldURL, ProfileFieldDate, ProfileFieldInteger:
+		default:
+			return nil, fmt.Errorf("profile field %q: unknown type %q", f.Name, f.Type)
+		}
+		if field.MaxLength < 0 {
+			return nil, fmt.Errorf("profile field %q: negative max_length", f.Name)
+		}
+		if field.MaxLength == 0 {
+			field.MaxLength = defaultProfileFieldMaxLength
+		}
+		if f.Pattern != "" {
+			re, err := regexp.Compile(`^(?:` + f.Pattern + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("profile field %q: %w", f.Name, err)
+			}
+			field.Pattern = re
+		}
+		if field.Visibility == "" {
+			field.Visibility = VisibilityPublic
+		}
+		if !field.Visibility.valid() {
+			return nil, fmt.Errorf("profile field %q: unknown visibility %q", f.Name, f.Visibility)
+		}
+		fields = append(fields, field)
+	}
+	return NewProfileSchema(fields)
+}
+
+// NewProfileSchema: 項目名の重複を確認してスキーマを作る
+func NewProfileSchema(fields []ProfileField) (*ProfileSchema, error) {
+	s := &ProfileSchema{Fields: fields, byName: make(map[string]*ProfileField, len(fields))}
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if _, dup := s.byName[f.Name]; dup {
+			return nil, fmt.Errorf("profile field %q: duplicated", f.Name)
+		}
+		s.byName[f.Name] = f
+	}
+	return s, nil
+}
+
+// Field: 定義を返す（未定義なら nil）。nil のスキーマは項目を持たない
+func (s *ProfileSchema) Field(name string) *ProfileField {
+	if s == nil {
+		return nil
+	}
+	return s.byName[name]
+}
+
+// validate: 空でない値が型・長さ・パターンを満たすか。満たさないものをすべて返す（field は応答での項目名）
+func (f *ProfileField) validate(field, v string) []FieldError {
+	var errs []FieldError
+	if e, ok := lengthError(field, utf8.RuneCountInString(v), 0, f.MaxLength); ok {
+		errs = append(errs, e)
+	}
+	if hasControl(v) {
+		errs = append(errs, FieldError{Field: field, Code: FieldInvalidCharacters})
+	}
+	valid := true
+	switch f.Type {
+	case ProfileFieldURL:
+		u, err := url.Parse(v)
+		valid = err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	case ProfileFieldDate:
+		_, err := time.Parse(time.DateOnly, v)
+		valid = err == nil
+	case ProfileFieldInteger:
+		_, err := strconv.ParseInt(v, 10, 64)
+		valid = err == nil
+	}
+	if !valid {
+		errs = append(errs, FieldError{Field: field, Code: FieldInvalidFormat, Params: map[string]any{"format": string(f.Type)}})
+	}
+	if f.Pattern != nil && !f.Pattern.MatchString(v) {
+		errs = append(errs, FieldError{Field: field, Code: FieldInvalidPattern, Params: map[string]any{"pattern": f.Pattern.String()}})
+	}
+	return errs
+}
+
+// ProfileUpdate: プロフィール更新の入力。nil の項目は変更しない
+type ProfileUpdate struct {
+	Nickname *string
+	Comment  *string
+	// Attributes: カスタム項目。値が nil または空文字の項目は削除する
+	Attributes map[string]*string
+}
+
+func (p ProfileUpdate) empty() bool {
+	return p.Nickname == nil && p.Comment == nil && len(p.Attributes) == 0
+}
+
+// ProfileSnapshot: ある時点のプロフィールの値
+type ProfileSnapshot struct {
+	Nickname   string
+	Comment    string
+	Attributes map[string]string
+}
+
+// Snapshot: 現在のプロフィールの値（Attributes は複製する）
+func (u *User) Snapshot() ProfileSnapshot {
+	s := ProfileSnapshot{Nickname: u.Nickname, Comment: u.Comment}
+	if len(u.Attributes) > 0 {
+		s.Attributes = make(map[string]string, len(u.Attributes))
+		for k, v := range u.Attributes {
+			s.Attributes[k] = v
+		}
+	}
+	return s
+}
+
+// Equal: 値が同じか
+func (s ProfileSnapshot) Equal(o ProfileSnapshot) bool {
+	if s.Nickname != o.Nickname || s.Comment != o.Comment || len(s.Attributes) != len(o.Attributes) {
+		return false
+	}
+	for k, v := range s.Attributes {
+		if ov, ok := o.Attributes[k]; !ok || ov != v {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateTo: current をこの値に置き換える更新内容（current にしかないカスタム項目は削除する）
+func (s ProfileSnapshot) UpdateTo(current ProfileSnapshot) ProfileUpdate {
+	nickname, comment := s.Nickname, s.Comment
+	upd := ProfileUpdate{Nickname: &nickname, Comment: &comment}
+	for k := range current.Attributes {
+		if _, ok := s.Attributes[k]; !ok {
+			if upd.Attributes == nil {
+				upd.Attributes = make(map[string]*string)
+			}
+			upd.Attributes[k] = nil
+		}
+	}
+	for k, v := range s.Attributes {
+		if upd.Attributes == nil {
+			upd.Attributes = make(map[string]*string)
+		}
+		upd.Attributes[k] = &v
+	}
+	return upd
+}
@@ -1,23 +1,257 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultTenant: テナントを使わない（単一テナントの）運用での名前空間
+const DefaultTenant = ""
 
 type UserRecord struct {
+	// Tenant: 所属するテナント。user_id はテナントの中でのみ一意
+	Tenant       string
 	UserID       string
 	PasswordHash string
 	Nickname     string
 	Comment      string
 	Deleted      bool
+	// Email: 任意。EmailVerified になるまでは確認トークン（ハッシュ値）と期限を保持する
+	Email               string
+	EmailVerified       bool
+	EmailTokenHash      string
+	EmailTokenExpiresAt time.Time
+	// EmailSentAt: 最後に確認メールを送った日時（再送の間隔の制限に使う）
+	EmailSentAt time.Time
+	// AvatarID: アップロード済みアバターの版。空文字は未設定
+	AvatarID string
+	// Attributes: カスタムプロフィール項目
+	Attributes map[string]string
+	// Privacy: プロフィールの公開範囲
+	Privacy PrivacySettings
+	// CreatedAt: 作成日時。UpdatedAt: プロフィール・メールアドレス・アバター・公開範囲の最終更新日時。
+	// LastLoginAt: パスワードによる最後の認証日時（未ログインはゼロ値）
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	LastLoginAt time.Time
+	// DeactivatedAt: 利用停止した日時（ゼロ値は利用中）
+	DeactivatedAt time.Time
 }
 
+// Profile: UpdateProfile で保存するプロフィール
+type Profile struct {
+	Nickname   string
+	Comment    string
+	Attributes map[string]string
+	// NicknamePolicy: nickname の重複をどこまで許すか（違反は ErrNicknameTaken / ErrNicknameConfusable）
+	NicknamePolicy NicknamePolicy
+}
+
+// NicknamePolicy: nickname の一意性の制約。比較は NicknameSkeleton で行う
+type NicknamePolicy struct {
+	// Unique: 他ユーザーの nickname と同じものを許さない
+	Unique bool
+	// RejectConfusable: 他ユーザーの nickname・user_id と紛らわしいものを許さない
+	RejectConfusable bool
+}
+
+// 各メソッドは tenant の名前空間だけを対象にする（user_id・確認済みメールアドレス・nickname・別名の一意性もテナントごと）。
+// 更新系のメソッドの at は UpdatedAt に記録する日時
 type UserRepository interface {
+	// Create: rec.Tenant の中で user_id が使用中、または rec.CreatedAt の時点で有効な別名なら ErrAlreadyExists
 	Create(rec *UserRecord) error
-	FindByID(userID string) (*UserRecord, error)
-	UpdateProfile(userID, nickname, comment string) error
-	Delete(userID string) error
+	FindByID(tenant, userID string) (*UserRecord, error)
+	// UpdateProfile: nickname の制約（p.NicknamePolicy）の確認と更新を不可分に行う
+	UpdateProfile(tenant, userID string, p Profile, at time.Time) error
+	// CheckNickname: userID のユーザーが nickname を使えるか（UpdateProfile と同じ判定。使えなければ ErrNicknameTaken / ErrNicknameConfusable）
+	CheckNickname(tenant, userID, nickname string, policy NicknamePolicy) error
+	// CheckEmail: userID のユーザーが email を設定できるか（他ユーザーが確認済みのアドレスは ErrAlreadyExists）
+	CheckEmail(tenant, userID, email string) error
+	// UpdateEmail: 未確認のメールアドレスと確認トークンを設定する（email 空文字で削除）。
+	// トークンを設定した場合は EmailSentAt を at にする。他ユーザーが確認済みのアドレスは ErrAlreadyExists
+	UpdateEmail(tenant, userID, email, tokenHash string, tokenExpiresAt, at time.Time) error
+	// MarkEmailVerified: email が現在の値と一致すれば確認済みにする。他ユーザーが確認済みなら ErrAlreadyExists
+	MarkEmailVerified(tenant, userID, email string, at time.Time) error
+	UpdateAvatar(tenant, userID, avatarID string, at time.Time) error
+	UpdatePrivacy(tenant, userID string, p PrivacySettings, at time.Time) error
+	// UpdateDeactivation: deactivated なら DeactivatedAt を at に、そうでなければゼロ値にする
+	UpdateDeactivation(tenant, userID string, deactivated bool, at time.Time) error
+	// RecordLogin: LastLoginAt を記録する（UpdatedAt は変えない）
+	RecordLogin(tenant, userID string, at time.Time) error
+	// Rename: レコードを newID に移し、oldID を aliasUntil まで newID への別名として残す。
+	// newID が使用中・有効な別名なら ErrAlreadyExists（自身の旧 user_id に戻す場合を除く）
+	Rename(tenant, oldID, newID string, aliasUntil, at time.Time) error
+	// ResolveAlias: at の時点で有効な別名の変更先。別名でなければ ErrNotFound
+	ResolveAlias(tenant, userID string, at time.Time) (string, error)
+	Delete(tenant, userID string) error
 }
 
 var (
 	ErrNotFound      = errors.New("not found")
 	ErrAlreadyExists = errors.New("already exists")
+	// nickname の一意性の違反（ErrAlreadyExists として扱える）
+	ErrNicknameTaken      = fmt.Errorf("%w: nickname is taken", ErrAlreadyExists)
+	ErrNicknameConfusable = fmt.Errorf("%w: nickname is confusable", ErrAlreadyExists)
 )
+
+// SessionRecord: ログインセッション。トークンはハッシュ値のみ保持する
+type SessionRecord struct {
+	ID         string
+	UserID     string
+	TokenHash  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	IP         string
+	UserAgent  string
+	// ImpersonatorID: 管理者によるなりすまし用セッションの場合、発行した管理者の user_id
+	ImpersonatorID string
+}
+
+type SessionRepository interface {
+	Create(rec *SessionRecord) error
+	FindByTokenHash(tokenHash string) (*SessionRecord, error)
+	ListByUser(userID string) ([]*SessionRecord, error)
+	Touch(id string, at time.Time, ip, userAgent string) error
+	Delete(userID, id string) error
+	DeleteByUser(userID string) error
+	// DeleteExpired: userID のセッションのうち ExpiresAt が at 以前のものを削除する
+	DeleteExpired(userID string, at time.Time) error
+	// RenameUser: user_id の変更に合わせて oldID のものを newID に移す（他の Repository も同じ）
+	RenameUser(oldID, newID string) error
+}
+
+// ActivityKind: 利用履歴に記録する操作の種別
+type ActivityKind string
+
+const (
+	ActivityLogin            ActivityKind = "login" // パスワードによる認証
+	ActivityProfileUpdate    ActivityKind = "profile_update"
+	ActivityEmailChange      ActivityKind = "email_change"
+	ActivityEmailVerify      ActivityKind = "email_verify"
+	ActivitySessionRevoke    ActivityKind = "session_revoke"
+	ActivitySessionRevokeAll ActivityKind = "session_revoke_all"
+	ActivityAvatarChange     ActivityKind = "avatar_change"
+	ActivityPrivacyChange    ActivityKind = "privacy_change"
+	ActivityUserRename       ActivityKind = "user_rename" // Detail は変更前の user_id
+	ActivityDeactivate       ActivityKind = "deactivate"
+	ActivityReactivate       ActivityKind = "reactivate"
+	ActivityDataExport       ActivityKind = "data_export"
+	// 管理者によるなりすましの開始と、なりすましセッションでのアクセス
+	ActivityImpersonationStart  ActivityKind = "impersonation_start"
+	ActivityImpersonationAccess ActivityKind = "impersonation_access"
+)
+
+type ActivityOutcome string
+
+const (
+	ActivitySuccess ActivityOutcome = "success"
+	ActivityFailure ActivityOutcome = "failure"
+)
+
+// ActivityRecord: アカウントごとの認証・変更の履歴。ID はストア内で単調増加する
+type ActivityRecord struct {
+	ID        uint64
+	UserID    string
+	Kind      ActivityKind
+	Outcome   ActivityOutcome
+	At        time.Time
+	IP        string
+	UserAgent string
+	// ActorID: 本人以外（なりすまし中の管理者）が操作した場合、その user_id
+	ActorID string
+	Detail  string
+	// Count: AppendCoalesced でまとめた件数（At・IP・UserAgent は最後のもの）。まとめていなければ 1
+	Count int
+}
+
+type ActivityRepository interface {
+	// Append: 記録する。保持上限（件数・期間）を超えた古い履歴はストアが削除する（期間は now を基準にする）
+	Append(rec *ActivityRecord, now time.Time) error
+	// AppendCoalesced: ユーザーの最新の履歴が rec と同じ種類・結果・操作者で、window 以内に記録したものなら、
+	// 新たに追加せずにその件数を増やし、日時・IP・User-Agent を rec のものにする（rec.ID はまとめた先の ID）。
+	// それ以外は Append と同じ
+	AppendCoalesced(rec *ActivityRecord, window time.Duration, now time.Time) error
+	// List: 新しい順に最大 limit 件。beforeID が 0 以外ならそれより古いもののみ。now 時点で保持期間を過ぎたものは除く
+	List(userID string, beforeID uint64, limit int, now time.Time) ([]*ActivityRecord, error)
+	DeleteByUser(userID string) error
+	RenameUser(oldID, newID string) error
+}
+
+// BlobStore: 画像などのバイナリの保存先。キーは "/" 区切りのパス
+type BlobStore interface {
+	Put(key string, data []byte) error
+	// Get: 存在しなければ ErrNotFound
+	Get(key string) ([]byte, error)
+	// DeletePrefix: prefix 配下をすべて削除する
+	DeletePrefix(prefix string) error
+	// List: prefix 直下の名前（prefix が存在しなければ空）
+	List(prefix string) ([]string, error)
+}
+
+// BlockRecord: OwnerID が TargetID をブロックしている
+type BlockRecord struct {
+	OwnerID   string
+	TargetID  string
+	CreatedAt time.Time
+}
+
+type BlockRepository interface {
+	// Add: 既にブロック済みなら ErrAlreadyExists
+	Add(rec *BlockRecord) error
+	// Remove: ブロックしていなければ ErrNotFound
+	Remove(ownerID, targetID string) error
+	Blocked(ownerID, targetID string) (bool, error)
+	// ListByOwner: 古い順
+	ListByOwner(ownerID string) ([]*BlockRecord, error)
+	// DeleteByUser: userID がブロックした・ブロックされたものをすべて削除する
+	DeleteByUser(userID string) error
+	RenameUser(oldID, newID string) error
+}
+
+// FollowRecord: FollowerID が FolloweeID をフォローしている。ID はストア内で単調増加する
+type FollowRecord struct {
+	ID         uint64
+	FollowerID string
+	FolloweeID string
+	CreatedAt  time.Time
+}
+
+type FollowRepository interface {
+	// Add: ID を採番して追加する。フォロー済みなら ErrAlreadyExists
+	Add(rec *FollowRecord) error
+	// Remove: フォローしていなければ ErrNotFound
+	Remove(followerID, followeeID string) error
+	// ListFollowers・ListFollowing: 新しい順に最大 limit 件。beforeID が 0 以外ならそれより古いもののみ
+	ListFollowers(userID string, beforeID uint64, limit int) ([]*FollowRecord, error)
+	ListFollowing(userID string, beforeID uint64, limit int) ([]*FollowRecord, error)
+	// DeleteByUser: userID がフォローした・フォローされたものをすべて削除する
+	DeleteByUser(userID string) error
+	RenameUser(oldID, newID string) error
+}
+
+// ProfileRevision: プロフィールの変更履歴。Rev はユーザーごとに 1 から単調増加する
+type ProfileRevision struct {
+	UserID string
+	Rev    uint64
+	// ActorID: 変更した user_id（本人、なりすまし中・審査で承認した管理者）
+	ActorID string
+	At      time.Time
+	Old     ProfileSnapshot
+	New     ProfileSnapshot
+	// RevertOf: 過去の版を戻した変更の場合、その版
+	RevertOf uint64
+}
+
+type ProfileRevisionRepository interface {
+	// Append: Rev を採番して追加する
+	Append(rec *ProfileRevision) error
+	// List: 新しい順に最大 limit 件。beforeRev が 0 以外ならそれより古いもののみ
+	List(userID string, beforeRev uint64, limit int) ([]*ProfileRevision, error)
+	// Find: 存在しなければ ErrNotFound
+	Find(userID string, rev uint64) (*ProfileRevision, error)
+	DeleteByUser(userID string) error
+	// RenameUser: UserID・ActorID とも移す
+	RenameUser(oldID, newID string) error
+}
@@ -1,44 +1,116 @@
 package domain
 
 import (
+	"net/mail"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 	"unicode/utf8"
 
+	"github.com/rivo/uniseg"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	UserID       string
-	PasswordHash string
-	Nickname     string
-	Comment      string
-	Deleted      bool
+	// Tenant: 所属するテナント（UserRecord と同じ）
+	Tenant        string
+	UserID        string
+	PasswordHash  string
+	Nickname      string
+	Comment       string
+	Deleted       bool
+	Email         string
+	EmailVerified bool
+	AvatarID      string
+	// Attributes: ProfileSchema で定義されたカスタム項目
+	Attributes map[string]string
+	Privacy    PrivacySettings
+	// 作成・最終更新・最終ログインの日時（UserRecord と同じ）
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	LastLoginAt time.Time
+	// DeactivatedAt: 利用停止した日時（ゼロ値は利用中）
+	DeactivatedAt time.Time
 }
 
+// Deactivated: 利用停止中か。利用停止中は他ユーザーから見えず、変更もできない
+func (u *User) Deactivated() bool { return !u.DeactivatedAt.IsZero() }
+
+// 長さは別に確認するため、使える文字のみを表す
 var (
-	reUserID = regexp.MustCompile(`^[A-Za-z0-9]{6,20}$`)
-	rePassOK = regexp.MustCompile(`^[\x21-\x7E]{8,20}$`) // 空白/制御を除く ASCII
+	reUserID = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	rePassOK = regexp.MustCompile(`^[\x21-\x7E]+$`) // 空白/制御を除く ASCII
 )
 
+// 未存在ユーザーの認証でも実ユーザーと同じコストの比較を行うためのハッシュ。
+// 起動時に一度だけ生成し、初回リクエストだけ遅くなることを避ける。
+var dummyPasswordHash = mustDummyHash()
+
+func mustDummyHash() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// NewUserForSignup: user_id 6..20・password 8..20 を検証する。失敗した項目はすべて Fields に含め、
+// Reason は従来どおり 必須 > 長さ > パターン の順で最初に当てはまるもの
 func NewUserForSignup(userID, rawPassword string) (*User, error) {
+	var fields []FieldError
 	// 必須チェック
-	if userID == "" || rawPassword == "" {
-		return nil, &ErrValidation{Reason: ValidationReasonCredentialRequired}
-	}
-	// 長さチェック
-	if l := len(userID); l < 6 || l > 20 {
-		return nil, &ErrValidation{Reason: ValidationReasonInputLength}
+	if userID == "" {
+		fields = append(fields, FieldError{Field: "user_id", Code: FieldRequired})
+	} else {
+		fields = append(fields, credentialErrors("user_id", userID, 6, 20, reUserID)...)
 	}
-	if l := len(rawPassword); l < 8 || l > 20 {
-		return nil, &ErrValidation{Reason: ValidationReasonInputLength}
+	if rawPassword == "" {
+		fields = append(fields, FieldError{Field: "password", Code: FieldRequired})
+	} else {
+		fields = append(fields, credentialErrors("password", rawPassword, 8, 20, rePassOK)...)
 	}
-	// パターンチェック
-	if !reUserID.MatchString(userID) || !rePassOK.MatchString(rawPassword) {
-		return nil, &ErrValidation{Reason: ValidationReasonInvalidPattern}
+	if len(fields) > 0 {
+		return nil, &ErrValidation{Reason: credentialReason(fields), Fields: fields}
 	}
 	return &User{UserID: userID}, nil
 }
 
+// ValidateUserID: 変更後の user_id の検証（サインアップと同じ規則）
+func ValidateUserID(userID string) error {
+	if fields := credentialErrors("user_id", userID, 6, 20, reUserID); len(fields) > 0 {
+		return &ErrValidation{Reason: credentialReason(fields), Fields: fields}
+	}
+	return nil
+}
+
+// credentialErrors: 長さ（min..max）と使える文字を確認する
+func credentialErrors(field, v string, min, max int, re *regexp.Regexp) []FieldError {
+	var errs []FieldError
+	if e, ok := lengthError(field, len(v), min, max); ok {
+		errs = append(errs, e)
+	}
+	if v != "" && !re.MatchString(v) {
+		errs = append(errs, FieldError{Field: field, Code: FieldInvalidPattern, Params: map[string]any{"pattern": re.String()}})
+	}
+	return errs
+}
+
+// credentialReason: user_id・password の失敗を 1 つの理由にまとめる（必須 > 長さ > パターン）
+func credentialReason(fields []FieldError) ValidationReason {
+	reason := ValidationReasonInvalidPattern
+	for _, f := range fields {
+		switch f.Code {
+		case FieldRequired:
+			return ValidationReasonCredentialRequired
+		case FieldTooShort, FieldTooLong:
+			reason = ValidationReasonInputLength
+		}
+	}
+	return reason
+}
+
 func (u *User) HashPassword(raw string) error {
 	hash, err := bcrypt.GenerateFromPassword([]byte(raw), bcrypt.DefaultCost)
 	if err != nil {
@@ -52,29 +124,151 @@ func (u *User) VerifyPassword(raw string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(raw)) == nil
 }
 
-// nickname: 0..30（制御コード禁止）。空文字→未設定（表示は user_id）
-// comment : 0..100（制御コード禁止）。空文字→クリア（未設定）
-func (u *User) ApplyProfileUpdate(nickname *string, comment *string) error {
-	if nickname == nil && comment == nil {
+// ValidPasswordHash: 設定で与えられたパスワードのハッシュ（bcrypt）として使えるか
+func ValidPasswordHash(hash string) bool {
+	_, err := bcrypt.Cost([]byte(hash))
+	return err == nil
+}
+
+// VerifyDummyPassword: ユーザーが存在しない場合に呼び出し、応答時間から user_id の存在が分からないようにする
+func VerifyDummyPassword(raw string) {
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(raw))
+}
+
+// nickname  : NFC 正規化後 0..30（制御コード・ゼロ幅文字・双方向制御禁止）。空文字→未設定（表示は user_id）
+// comment   : 0..100（制御コード禁止）。空文字→クリア（未設定）
+// attributes: schema に定義された項目のみ。空文字・null→削除。required の項目は更新後に空であってはならない
+func (u *User) ApplyProfileUpdate(upd ProfileUpdate, schema *ProfileSchema) error {
+	if upd.empty() {
 		return &ErrValidation{Reason: ValidationReasonProfileRequired}
 	}
-	if nickname != nil {
-		if !withinLen(*nickname, 0, 30) || hasControl(*nickname) {
-			return &ErrValidation{Reason: ValidationReasonProfileConstraint}
-		}
+	// すべての項目を検証してから反映する（失敗した項目はすべて返す）
+	var errs validationErrors
+	nickname := u.Nickname
+	if upd.Nickname != nil {
+		nn, err := ValidateNickname(*upd.Nickname)
 		// 空文字 = 未設定（保存は空文字のまま）
-		u.Nickname = *nickname
+		nickname = nn
+		errs.merge(err)
 	}
-	if comment != nil {
-		if !withinLen(*comment, 0, 100) || hasControl(*comment) {
-			return &ErrValidation{Reason: ValidationReasonProfileConstraint}
+	if upd.Comment != nil {
+		var fields []FieldError
+		if e, ok := lengthError("comment", utf8.RuneCountInString(*upd.Comment), 0, 100); ok {
+			fields = append(fields, e)
+		}
+		if hasControl(*upd.Comment) {
+			fields = append(fields, FieldError{Field: "comment", Code: FieldInvalidCharacters})
+		}
+		if len(fields) > 0 {
+			errs.add(ValidationReasonProfileConstraint, fields...)
 		}
+	}
+	attrs := u.Attributes
+	if len(upd.Attributes) > 0 {
+		attrs = make(map[string]string, len(u.Attributes)+len(upd.Attributes))
+		for k, v := range u.Attributes {
+			attrs[k] = v
+		}
+		names := make([]string, 0, len(upd.Attributes))
+		for name := range upd.Attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v, key := upd.Attributes[name], "attributes."+name
+			field := schema.Field(name)
+			if field == nil {
+				errs.add(ValidationReasonProfileAttributeUnknown, FieldError{Field: key, Code: FieldUnknown})
+				continue
+			}
+			if v == nil || *v == "" {
+				delete(attrs, name)
+				continue
+			}
+			if fields := field.validate(key, *v); len(fields) > 0 {
+				errs.add(ValidationReasonProfileAttributeInvalid, fields...)
+				continue
+			}
+			attrs[name] = *v
+		}
+	}
+	if schema != nil {
+		// 更新に含まれる項目だけを確認する（必須項目を後から追加しても、未入力の既存ユーザーの他の項目の更新を妨げない）
+		for _, f := range schema.Fields {
+			if _, updated := upd.Attributes[f.Name]; !updated {
+				continue
+			}
+			if f.Required && attrs[f.Name] == "" && !errs.has("attributes."+f.Name) {
+				errs.add(ValidationReasonProfileAttributeRequired, FieldError{Field: "attributes." + f.Name, Code: FieldRequired})
+			}
+		}
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	if upd.Nickname != nil {
+		u.Nickname = nickname
+	}
+	if upd.Comment != nil {
 		// 空文字 = クリア
-		u.Comment = *comment
+		u.Comment = *upd.Comment
 	}
+	u.Attributes = attrs
 	return nil
 }
 
+// email: 0..254（RFC 5322 のアドレス部のみ。表示名は不可）。空文字→削除
+// 変更すると未確認に戻る
+func (u *User) SetEmail(raw string) error {
+	email := strings.TrimSpace(raw)
+	if email == "" {
+		u.Email = ""
+		u.EmailVerified = false
+		return nil
+	}
+	if len(email) > 254 || hasControl(email) {
+		return &ErrValidation{Reason: ValidationReasonEmailInvalid}
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return &ErrValidation{Reason: ValidationReasonEmailInvalid}
+	}
+	at := strings.LastIndex(email, "@")
+	domainPart := strings.ToLower(email[at+1:])
+	if !strings.Contains(domainPart, ".") {
+		return &ErrValidation{Reason: ValidationReasonEmailInvalid}
+	}
+	// ドメイン部は大文字小文字を区別しない
+	email = email[:at+1] + domainPart
+	if email != u.Email {
+		u.EmailVerified = false
+	}
+	u.Email = email
+	return nil
+}
+
+// ValidateNickname: NFC に正規化し、長さ（見た目の文字数＝書記素クラスタ数で 0..30）と
+// 使用できない文字（C0・DEL・C1 の制御コード、ゼロ幅文字・双方向制御）を確認する
+func ValidateNickname(raw string) (string, error) {
+	nn := NormalizeNickname(raw)
+	var fields []FieldError
+	if e, ok := lengthError("nickname", uniseg.GraphemeClusterCount(nn), 0, 30); ok {
+		fields = append(fields, e)
+	}
+	if strings.IndexFunc(nn, unicode.IsControl) >= 0 || hasFormatChar(nn) {
+		fields = append(fields, FieldError{Field: "nickname", Code: FieldInvalidCharacters})
+	}
+	if len(fields) > 0 {
+		return "", &ErrValidation{Reason: ValidationReasonProfileConstraint, Fields: fields}
+	}
+	return nn, nil
+}
+
+// EmailKey: メールアドレスの一意性判定に使うキー
+func EmailKey(email string) string {
+	return strings.ToLower(email)
+}
+
 func withinLen(s string, min, max int) bool {
 	l := utf8.RuneCountInString(s)
 	return l >= min && l <= max
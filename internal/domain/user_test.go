@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateNicknameCountsGraphemes(t *testing.T) {
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467" // ZWJ で結合した 1 文字
+	tests := []struct {
+		name string
+		in   string
+		ok   bool
+	}{
+		{"30 graphemes", strings.Repeat("が", 30), true},
+		{"decomposed counts once", strings.Repeat("か\u3099", 30), true},
+		{"combining sequences", strings.Repeat("q\u0301", 30), true},
+		{"31 graphemes", strings.Repeat("a", 31), false},
+		{"C1 control", "taro\u0085", false},
+		{"zero width", "ta\u200bro", false},
+		{"ZWJ sequence", family, false}, // ZWJ は Cf のため使用できない
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateNickname(tt.in)
+			if (err == nil) != tt.ok {
+				t.Errorf("ValidateNickname(%q) err = %v, want ok=%v", tt.in, err, tt.ok)
+			}
+		})
+	}
+}
+
+// nickname 以外の項目は従来の規則（コードポイント数・ASCII 制御のみ禁止）のまま
+func TestOtherFieldsKeepCodePointRules(t *testing.T) {
+	// 合成済みの文字が無い結合文字列は、書記素では 50 文字、コードポイントでは 100 文字
+	if _, err := NormalizeGroupName(strings.Repeat("q\u0301", 50)); err == nil {
+		t.Error("group name: 100 code points accepted, want the 50 code point limit")
+	}
+	if _, err := NormalizeGroupName("team\u0085"); err != nil {
+		t.Errorf("group name: C1 control rejected: %v", err)
+	}
+
+	u := &User{UserID: "TaroYamada"}
+	comment := strings.Repeat("q\u0301", 51)
+	if err := u.ApplyProfileUpdate(ProfileUpdate{Comment: &comment}, nil); err == nil {
+		t.Error("comment: 102 code points accepted, want the 100 code point limit")
+	}
+	comment = "line\u0085"
+	if err := u.ApplyProfileUpdate(ProfileUpdate{Comment: &comment}, nil); err != nil {
+		t.Errorf("comment: C1 control rejected: %v", err)
+	}
+}
+
+// 必須項目は更新に含まれる場合だけ確認する（後から追加した必須項目で既存ユーザーの更新を妨げない）
+func TestRequiredAttributeCheckedOnlyWhenUpdated(t *testing.T) {
+	schema, err := ParseProfileSchema([]byte(`{"fields": [{"name": "pronouns", "required": true}, {"name": "city"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &User{UserID: "TaroYamada"}
+	nickname, city := "Taro", "Tokyo"
+	if err := u.ApplyProfileUpdate(ProfileUpdate{Nickname: &nickname}, schema); err != nil {
+		t.Errorf("nickname only: %v", err)
+	}
+	if err := u.ApplyProfileUpdate(ProfileUpdate{Attributes: map[string]*string{"city": &city}}, schema); err != nil {
+		t.Errorf("other attribute only: %v", err)
+	}
+
+	requiredErr := func(err error) bool {
+		var vErr *ErrValidation
+		return errors.As(err, &vErr) && vErr.Reason == ValidationReasonProfileAttributeRequired
+	}
+	empty := ""
+	for name, v := range map[string]*string{"null": nil, "empty": &empty} {
+		if err := u.ApplyProfileUpdate(ProfileUpdate{Attributes: map[string]*string{"pronouns": v}}, schema); !requiredErr(err) {
+			t.Errorf("clearing with %s: err = %v, want attribute required", name, err)
+		}
+	}
+	pronouns := "he/him"
+	if err := u.ApplyProfileUpdate(ProfileUpdate{Attributes: map[string]*string{"pronouns": &pronouns}}, schema); err != nil {
+		t.Errorf("setting: %v", err)
+	}
+	if u.Attributes["pronouns"] != pronouns || u.Attributes["city"] != city {
+		t.Errorf("attributes = %v", u.Attributes)
+	}
+}
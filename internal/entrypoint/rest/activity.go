@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"accountapi/internal/usecase"
+)
+
+// GET /users/{user_id}/activity?limit=&cursor=（本人のみ）
+func (s *Server) handleUserActivity(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	limit, cursor, ok := pageParams(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Activity retrieval failed", "Invalid limit or cursor"})
+		return
+	}
+	list, next, err := s.uc(r).ListActivity(pathUserID, cred, cursor, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNoPerm):
+			writeJSON(w, http.StatusForbidden, messageOnly{Message: "No permission for access"})
+		case errors.Is(err, usecase.ErrAuthFailed):
+			writeAuthFailed(w)
+		case errors.Is(err, usecase.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+		case errors.Is(err, usecase.ErrBusy):
+			s.writeBusy(w)
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	resp := activityListResponse{Message: "Account activity", Activity: make([]activityDetail, 0, len(list))}
+	for _, a := range list {
+		resp.Activity = append(resp.Activity, activityDetail{
+			ID:        strconv.FormatUint(a.ID, 10),
+			Kind:      string(a.Kind),
+			Outcome:   string(a.Outcome),
+			At:        a.At.UTC().Format(time.RFC3339),
+			IP:        a.IP,
+			UserAgent: a.UserAgent,
+			ActorID:   a.ActorID,
+			Detail:    a.Detail,
+			Count:     coalescedCount(a),
+		})
+	}
+	if next != 0 {
+		resp.NextCursor = strconv.FormatUint(next, 10)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// coalescedCount: まとめた記録の件数。1 件だけなら 0（出力しない）
+func coalescedCount(a *usecase.Activity) int {
+	if a.Count > 1 {
+		return a.Count
+	}
+	return 0
+}
+
+// pageParams: ?limit=（1 以上）&cursor=（前ページの next_cursor）
+func pageParams(r *http.Request) (limit int, cursor uint64, ok bool) {
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, false
+		}
+		limit = n
+	}
+	if v := q.Get("cursor"); v != "" {
+		c, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || c == 0 {
+			return 0, 0, false
+		}
+		cursor = c
+	}
+	return limit, cursor, true
+}
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// ParseAdminAccounts: 管理者のアカウントの JSON（user_id → パスワードハッシュ（bcrypt））を読み込む
+//
+//	{"admin01": "$2a$10$..."}
+func ParseAdminAccounts(data []byte) (map[string]string, error) {
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for id, hash := range m {
+		if !domain.ValidPasswordHash(hash) {
+			return nil, fmt.Errorf("invalid password hash for %q", id)
+		}
+	}
+	return m, nil
+}
+
+// POST /admin/impersonations（管理者のみ）
+// 対象ユーザーとして振る舞う短時間のトークンを発行する
+func (s *Server) handleImpersonations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+	var req impersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Impersonation failed", "Required user_id and reason"})
+		return
+	}
+	sess, err := s.uc(r).Impersonate(cred, req.UserID, req.Reason)
+	if err != nil {
+		var vErr *usecase.ValidationError
+		switch {
+		case errors.As(err, &vErr):
+			writeJSON(w, http.StatusBadRequest, newValidationFailure("Impersonation failed", vErr))
+		case errors.Is(err, usecase.ErrAuthFailed):
+			writeAuthFailed(w)
+		case errors.Is(err, usecase.ErrNoPerm):
+			writeJSON(w, http.StatusForbidden, messageOnly{Message: "No permission for impersonation"})
+		case errors.Is(err, usecase.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+		case errors.Is(err, usecase.ErrBusy):
+			s.writeBusy(w)
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, sessionCreatedResponse{
+		Message: "Impersonation session successfully created",
+		Session: toSessionDetail(sess),
+		Token:   sess.Token,
+	})
+}
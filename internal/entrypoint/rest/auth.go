@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"accountapi/internal/usecase"
+)
+
+// credential: 認証情報を取り出す。Authorization ヘッダ（Bearer を優先し、なければ Basic）があればそれを使い、
+// なければ検証済みのクライアント証明書に対応する user_id を使う
+func (s *Server) credential(r *http.Request) (usecase.Credential, bool) {
+	cred := usecase.Credential{Client: s.clientInfo(r)}
+	if token, ok := bearerToken(r); ok {
+		cred.Token = token
+		return cred, true
+	}
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		if userID, ok := s.certUserID(r); ok {
+			cred.UserID = userID
+			cred.ClientCert = true
+			return cred, true
+		}
+		return usecase.Credential{}, false
+	}
+	cred.UserID = user
+	cred.Password = pass
+	return cred, true
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(h[len(prefix):])
+	return token, token != ""
+}
+
+func (s *Server) clientInfo(r *http.Request) usecase.ClientInfo {
+	return usecase.ClientInfo{IP: s.clientIP(r), UserAgent: r.UserAgent()}
+}
+
+// clientIP: 接続元 IP。プロキシ配下では、プロキシが末尾に追記した X-Forwarded-For の値を使う
+func (s *Server) clientIP(r *http.Request) string {
+	if s.trustProxyHeaders {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			parts := strings.Split(xff, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
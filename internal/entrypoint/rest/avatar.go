@@ -0,0 +1,247 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// /users/{user_id}/avatar
+//
+//	PUT    : アップロード（multipart/form-data の avatar フィールド、または image/png・image/jpeg・image/gif の本文）
+//	DELETE : 削除
+//	GET    : 画像の取得（認証不要。?size= でサムネイル）
+func (s *Server) handleUserAvatar(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	switch r.Method {
+	case http.MethodGet:
+		s.serveAvatar(w, r, pathUserID)
+	case http.MethodPut:
+		cred, ok := s.credential(r)
+		if !ok {
+			writeAuthFailed(w)
+			return
+		}
+		data, status, cause := readAvatarUpload(w, r)
+		if status != http.StatusOK {
+			writeJSON(w, status, struct {
+				Message string `json:"message"`
+				Cause   string `json:"cause"`
+			}{"Avatar upload failed", cause})
+			return
+		}
+		u, err := s.uc(r).SetAvatar(pathUserID, cred, data)
+		if err != nil {
+			s.writeAvatarError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, userResponse{Message: "Avatar successfully updated", User: toUserDetail(r, u, true)})
+	case http.MethodDelete:
+		cred, ok := s.credential(r)
+		if !ok {
+			writeAuthFailed(w)
+			return
+		}
+		u, err := s.uc(r).DeleteAvatar(pathUserID, cred)
+		if err != nil {
+			s.writeAvatarError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, userResponse{Message: "Avatar successfully removed", User: toUserDetail(r, u, true)})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// readAvatarUpload: 本文から画像のバイト列を取り出す。失敗時は応答ステータスと理由を返す
+func readAvatarUpload(w http.ResponseWriter, r *http.Request) ([]byte, int, string) {
+	// multipart のヘッダ分の余裕を持たせる
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxAvatarBytes+64<<10)
+	defer r.Body.Close()
+
+	var src io.Reader
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, http.StatusBadRequest, "Required avatar image"
+		}
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				return nil, http.StatusBadRequest, "Required avatar image"
+			}
+			if part.FormName() == "avatar" {
+				src = part
+				break
+			}
+		}
+	case "image/png", "image/jpeg", "image/gif":
+		src = r.Body
+	default:
+		return nil, http.StatusUnsupportedMediaType, validationCause(usecase.ValidationReasonAvatarFormat)
+	}
+	data, err := io.ReadAll(io.LimitReader(src, domain.MaxAvatarBytes+1))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, http.StatusRequestEntityTooLarge, validationCause(usecase.ValidationReasonAvatarTooLarge)
+		}
+		return nil, http.StatusBadRequest, "Required avatar image"
+	}
+	if len(data) > domain.MaxAvatarBytes {
+		return nil, http.StatusRequestEntityTooLarge, validationCause(usecase.ValidationReasonAvatarTooLarge)
+	}
+	return data, http.StatusOK, ""
+}
+
+func (s *Server) serveAvatar(w http.ResponseWriter, r *http.Request, userID string) {
+	size := 0
+	if v := r.URL.Query().Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		size = n
+	}
+	// 認証情報があれば閲覧者として扱う（ブロック・公開範囲を確認する）。無ければ公開プロフィールのみ
+	var cred *usecase.Credential
+	if c, ok := s.credential(r); ok {
+		cred = &c
+	}
+	img, err := s.uc(r).Avatar(userID, cred, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrAuthFailed):
+			writeAuthFailed(w)
+		case errors.Is(err, usecase.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, messageOnly{Message: "No avatar found"})
+		case errors.Is(err, usecase.ErrBusy):
+			s.writeBusy(w)
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	data, version := img.Data, img.Version
+	etag := `"` + version + "-" + strconv.Itoa(size) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Authorization")
+	// 公開プロフィール以外は閲覧者ごとに異なるため、共有キャッシュには載せない
+	scope := "public"
+	if !img.Public {
+		scope = "private"
+	}
+	// 版付き URL（?v=）は内容が変わらないため長期キャッシュできる
+	if r.URL.Query().Get("v") == version {
+		w.Header().Set("Cache-Control", scope+", max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", scope+", no-cache")
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.Contains(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) writeAvatarError(w http.ResponseWriter, err error) {
+	var vErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		status := http.StatusBadRequest
+		if vErr.Reason == usecase.ValidationReasonAvatarTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(w, status, newValidationFailure("Avatar upload failed", vErr))
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for update")
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// GET /users/{user_id}/identicon.png|svg?size=（認証不要。user_id のみから決まるためユーザーの有無は確認しない）
+func (s *Server) handleUserIdenticon(w http.ResponseWriter, r *http.Request, userID string, svg bool) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	size := 0
+	if v := r.URL.Query().Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n == 0 {
+			writeJSON(w, http.StatusBadRequest, struct {
+				Message string `json:"message"`
+				Cause   string `json:"cause"`
+			}{"Identicon generation failed", validationCause(usecase.ValidationReasonIdenticonSize)})
+			return
+		}
+		size = n
+	}
+	if size == 0 {
+		size = domain.IdenticonDefaultSize
+	}
+	// user_id だけで決まるためテナントを問わず共有する
+	key := identiconKey{userID: userID, size: size, svg: svg}
+	ic, ok := s.identicons.get(key)
+	if !ok {
+		data, err := s.uc(r).Identicon(userID, size, svg)
+		if err != nil {
+			var vErr *usecase.ValidationError
+			switch {
+			case errors.As(err, &vErr):
+				writeJSON(w, http.StatusBadRequest, newValidationFailure("Identicon generation failed", vErr))
+			case errors.Is(err, usecase.ErrBusy):
+				s.writeBusy(w)
+			default:
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			return
+		}
+		sum := sha256.Sum256(data)
+		ic = &identiconEntry{key: key, data: data, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+		s.identicons.add(ic)
+	}
+	contentType := "image/png"
+	if svg {
+		contentType = "image/svg+xml"
+	}
+	w.Header().Set("ETag", ic.etag)
+	w.Header().Set("Cache-Control", "public, max-age=604800")
+	if match := r.Header.Get("If-None-Match"); match != "" && strings.Contains(match, ic.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(ic.data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(ic.data)
+}
+
+// avatarURL: 版付きのアバター URL。未アップロードなら identicon の URL
+func avatarURL(r *http.Request, u *domain.User) string {
+	if u.AvatarID == "" {
+		return userPath(r, u.UserID) + "/identicon.png"
+	}
+	return userPath(r, u.UserID) + "/avatar?v=" + url.QueryEscape(u.AvatarID)
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"accountapi/internal/usecase"
+)
+
+// GET /users/{user_id}/blocks?sort=（本人のみ）
+func (s *Server) handleUserBlocks(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	order, ok := usecase.ParseUserSort(r.URL.Query().Get("sort"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Block list retrieval failed", "Invalid sort"})
+		return
+	}
+	list, err := s.uc(r).ListBlocks(pathUserID, cred, order)
+	if err != nil {
+		s.writeRelationshipError(w, err, "Block list retrieval failed")
+		return
+	}
+	resp := blockListResponse{Message: "Blocked users", Blocks: make([]blockDetail, 0, len(list))}
+	for _, b := range list {
+		resp.Blocks = append(resp.Blocks, blockDetail{UserID: b.TargetID, CreatedAt: b.CreatedAt.UTC().Format(time.RFC3339)})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST/DELETE /users/{user_id}/blocks/{target}（本人のみ）
+func (s *Server) handleUserBlock(w http.ResponseWriter, r *http.Request, pathUserID, targetID string) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		if err := s.uc(r).Unblock(pathUserID, cred, targetID); err != nil {
+			s.writeRelationshipError(w, err, "Unblock failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, messageOnly{Message: "User successfully unblocked"})
+		return
+	}
+	if _, err := s.uc(r).Block(pathUserID, cred, targetID); err != nil {
+		s.writeRelationshipError(w, err, "Block failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, messageOnly{Message: "User successfully blocked"})
+}
+
+// writeRelationshipError: ブロック・フォロー関連の API のエラー応答
+func (s *Server) writeRelationshipError(w http.ResponseWriter, err error, failure string) {
+	var vErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		writeJSON(w, http.StatusBadRequest, newValidationFailure(failure, vErr))
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for update")
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CertUserMap: クライアント証明書の識別子から user_id への対応表。
+// キーは "CN:<common name>"・"DNS:<SAN>"・"URI:<SAN>"・"EMAIL:<SAN>" のいずれか
+type CertUserMap map[string]string
+
+// ParseCertUserMap: JSON オブジェクト（{"CN:billing": "BillingSvc01", ...}）を読み込む
+func ParseCertUserMap(data []byte) (CertUserMap, error) {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	m := make(CertUserMap, len(raw))
+	for k, v := range raw {
+		kind, value, ok := strings.Cut(k, ":")
+		if !ok || value == "" || v == "" {
+			return nil, fmt.Errorf("invalid certificate mapping %q", k)
+		}
+		kind = strings.ToUpper(kind)
+		switch kind {
+		case "CN", "DNS", "URI", "EMAIL":
+		default:
+			return nil, fmt.Errorf("invalid certificate mapping %q: unknown kind %q", k, kind)
+		}
+		m[kind+":"+value] = v
+	}
+	return m, nil
+}
+
+// lookup: SAN（URI・DNS・EMAIL）、Subject CN の順に対応表を引く
+func (m CertUserMap) lookup(cert *x509.Certificate) (string, bool) {
+	var keys []string
+	for _, u := range cert.URIs {
+		keys = append(keys, "URI:"+u.String())
+	}
+	for _, d := range cert.DNSNames {
+		keys = append(keys, "DNS:"+d)
+	}
+	for _, e := range cert.EmailAddresses {
+		keys = append(keys, "EMAIL:"+e)
+	}
+	if cert.Subject.CommonName != "" {
+		keys = append(keys, "CN:"+cert.Subject.CommonName)
+	}
+	for _, k := range keys {
+		if userID, ok := m[k]; ok {
+			return userID, true
+		}
+	}
+	return "", false
+}
+
+// certUserID: TLS 層で検証済みのクライアント証明書があれば、対応する user_id を返す
+func (s *Server) certUserID(r *http.Request) (string, bool) {
+	if len(s.certUsers) == 0 || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return s.certUsers.lookup(r.TLS.VerifiedChains[0][0])
+}
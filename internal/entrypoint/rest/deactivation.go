@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// POST /users/{user_id}/deactivate・/reactivate（本人のみ）
+func (s *Server) handleUserActivation(w http.ResponseWriter, r *http.Request, pathUserID string, deactivate bool) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	var (
+		u       *domain.User
+		err     error
+		message string
+	)
+	if deactivate {
+		u, err = s.uc(r).Deactivate(pathUserID, cred)
+		message = "Account successfully deactivated"
+	} else {
+		u, err = s.uc(r).Reactivate(pathUserID, cred)
+		message = "Account successfully reactivated"
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNoPerm):
+			writeForbidden(w, err, "No permission for update")
+		case errors.Is(err, usecase.ErrAuthFailed):
+			writeAuthFailed(w)
+		case errors.Is(err, usecase.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+		case errors.Is(err, usecase.ErrBusy):
+			s.writeBusy(w)
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, userResponse{Message: message, User: toUserDetail(r, u, true)})
+}
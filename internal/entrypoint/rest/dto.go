@@ -5,6 +5,19 @@ type messageOnly struct {
 	Message string `json:"message"`
 }
 
+// 検証エラーの 400 応答。errors は失敗したすべての項目（項目ごとに検証しない失敗では省略）
+type validationFailure struct {
+	Message string             `json:"message"`
+	Cause   string             `json:"cause"`
+	Errors  []fieldErrorDetail `json:"errors,omitempty"`
+}
+
+type fieldErrorDetail struct {
+	Field  string         `json:"field"`
+	Code   string         `json:"code"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
 // /signup 入力
 type signUpRequest struct {
 	UserID   string `json:"user_id"`
@@ -29,15 +42,183 @@ type userResponse struct {
 }
 
 type userDetail struct {
-	UserID   string  `json:"user_id"`
-	Nickname string  `json:"nickname"`
-	Comment  *string `json:"comment,omitempty"`
+	UserID        string  `json:"user_id"`
+	Nickname      string  `json:"nickname"`
+	Comment       *string `json:"comment,omitempty"`
+	Email         *string `json:"email,omitempty"`          // 本人のみ
+	EmailVerified *bool   `json:"email_verified,omitempty"` // 本人のみ
+	AvatarURL     string  `json:"avatar_url,omitempty"`
+	// Attributes: カスタムプロフィール項目（他ユーザーには公開範囲内のもののみ）
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// フォロワー数・フォロー数（GET /users/{user_id} のみ）
+	FollowersCount *int `json:"followers_count,omitempty"`
+	FollowingCount *int `json:"following_count,omitempty"`
+	// 作成・最終更新日時（RFC 3339）。最終ログイン日時は本人のみ
+	CreatedAt   string `json:"created_at,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+	LastLoginAt string `json:"last_login_at,omitempty"`
+	// DeactivatedAt: 利用停止中の場合、その日時（本人のみ）
+	DeactivatedAt string `json:"deactivated_at,omitempty"`
 }
 
 // PATCH 入力
 type updateUserRequest struct {
 	Nickname *string `json:"nickname,omitempty"`
 	Comment  *string `json:"comment,omitempty"`
-	UserID   *string `json:"user_id,omitempty"`
-	Password *string `json:"password,omitempty"`
+	// Attributes: カスタムプロフィール項目。null または空文字で削除
+	Attributes map[string]*string `json:"attributes,omitempty"`
+	UserID     *string            `json:"user_id,omitempty"`
+	Password   *string            `json:"password,omitempty"`
+}
+
+// PUT /users/{user_id}/user_id 入力
+type renameUserRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// PUT /users/{user_id}/email 入力
+type setEmailRequest struct {
+	Email *string `json:"email"`
+}
+
+// POST /users/{user_id}/email/verify 入力
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// POST /sessions 出力
+type sessionCreatedResponse struct {
+	Message string        `json:"message"`
+	Session sessionDetail `json:"session"`
+	Token   string        `json:"token"`
+}
+
+// GET /sessions 出力
+type sessionListResponse struct {
+	Message  string          `json:"message"`
+	Sessions []sessionDetail `json:"sessions"`
+}
+
+type sessionDetail struct {
+	ID         string `json:"id"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	ExpiresAt  string `json:"expires_at"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	Current    bool   `json:"current"`
+	// ImpersonatedBy: 管理者によるなりすましセッションの場合、その管理者の user_id
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
+}
+
+// PUT /users/{user_id}/privacy 入力
+type privacyRequest struct {
+	Profile string            `json:"profile"`
+	Fields  map[string]string `json:"fields"`
+}
+
+// GET/PUT /users/{user_id}/privacy 出力
+type privacyResponse struct {
+	Message string        `json:"message"`
+	Privacy privacyDetail `json:"privacy"`
+}
+
+type privacyDetail struct {
+	Profile string            `json:"profile"`
+	Fields  map[string]string `json:"fields"`
+}
+
+// GET /users/{user_id}/blocks 出力
+type blockListResponse struct {
+	Message string        `json:"message"`
+	Blocks  []blockDetail `json:"blocks"`
+}
+
+type blockDetail struct {
+	UserID    string `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+// GET /users/{user_id}/followers・/following 出力
+type followListResponse struct {
+	Message    string         `json:"message"`
+	Users      []followDetail `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type followDetail struct {
+	UserID     string `json:"user_id"`
+	FollowedAt string `json:"followed_at"`
+}
+
+// GET /users/{user_id}/history 出力
+type historyListResponse struct {
+	Message    string           `json:"message"`
+	History    []revisionDetail `json:"history"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+type revisionDetail struct {
+	Rev      uint64         `json:"rev"`
+	ActorID  string         `json:"actor_id"`
+	At       string         `json:"at"`
+	Old      snapshotDetail `json:"old"`
+	New      snapshotDetail `json:"new"`
+	RevertOf uint64         `json:"revert_of,omitempty"`
+}
+
+// 変更前後の値（未設定は空文字）
+type snapshotDetail struct {
+	Nickname   string            `json:"nickname"`
+	Comment    string            `json:"comment"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// GET /nicknames/{nickname}/availability 出力
+type nicknameAvailabilityResponse struct {
+	Message   string `json:"message"`
+	Nickname  string `json:"nickname"`
+	Available bool   `json:"available"`
+	Cause     string `json:"cause,omitempty"` // 使えない理由
+}
+
+// GET /admin/moderation 出力
+type moderationListResponse struct {
+	Message string             `json:"message"`
+	Pending []moderationDetail `json:"pending"`
+}
+
+type moderationDetail struct {
+	ID         string             `json:"id"`
+	UserID     string             `json:"user_id"`
+	Nickname   *string            `json:"nickname,omitempty"`
+	Comment    *string            `json:"comment,omitempty"`
+	Attributes map[string]*string `json:"attributes,omitempty"`
+	Matches    []string           `json:"matches"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+// GET /users/{user_id}/activity 出力
+type activityListResponse struct {
+	Message    string           `json:"message"`
+	Activity   []activityDetail `json:"activity"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+type activityDetail struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Outcome   string `json:"outcome"`
+	At        string `json:"at"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	ActorID   string `json:"actor_id,omitempty"` // なりすまし中の管理者
+	Detail    string `json:"detail,omitempty"`
+	Count     int    `json:"count,omitempty"` // 続けて失敗した認証をまとめた件数（2 以上のときのみ）
+}
+
+// POST /admin/impersonations 入力
+type impersonateRequest struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
 }
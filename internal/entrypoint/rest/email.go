@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"accountapi/internal/usecase"
+)
+
+// PUT /users/{user_id}/email
+func (s *Server) handleUserEmail(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+	var req setEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == nil {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Email update failed", "Required email"})
+		return
+	}
+	u, err := s.uc(r).SetEmail(pathUserID, cred, *req.Email)
+	if err != nil {
+		s.writeEmailError(w, err, "Email update failed")
+		return
+	}
+	msg := "Email successfully removed"
+	if u.Email != "" {
+		msg = "Verification email sent"
+		if u.EmailVerified {
+			msg = "Email already verified"
+		}
+	}
+	writeJSON(w, http.StatusOK, userResponse{Message: msg, User: toUserDetail(r, u, true)})
+}
+
+// POST /users/{user_id}/email/verify（トークン自体が認証となるため Authorization 不要）
+func (s *Server) handleUserEmailVerify(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+	var req verifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Email verification failed", validationCause(usecase.ValidationReasonEmailTokenInvalid)})
+		return
+	}
+	if _, err := s.uc(r).VerifyEmail(pathUserID, req.Token, s.clientInfo(r)); err != nil {
+		s.writeEmailError(w, err, "Email verification failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, messageOnly{Message: "Email successfully verified"})
+}
+
+func (s *Server) writeEmailError(w http.ResponseWriter, err error, failure string) {
+	var vErr *usecase.ValidationError
+	var limited *usecase.RateLimitedError
+	switch {
+	case errors.As(err, &vErr):
+		writeJSON(w, http.StatusBadRequest, newValidationFailure(failure, vErr))
+	case errors.As(err, &limited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		writeJSON(w, http.StatusTooManyRequests, messageOnly{Message: "Too many verification emails"})
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for update")
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// RunExportSweeper: 起動直後と interval ごとに、すべてのテナントの期限切れ・記録のないアーカイブを削除する（ctx の終了まで）
+func (s *Server) RunExportSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, uc := range s.usecases() {
+			if err := uc.SweepExports(); err != nil {
+				log.Printf("export sweep (tenant %q): %v", uc.Tenant, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// POST /users/{user_id}/exports（本人のみ）
+func (s *Server) handleUserExports(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	rec, err := s.uc(r).RequestExport(pathUserID, cred)
+	if err != nil {
+		s.writeExportError(w, err, "No user found")
+		return
+	}
+	w.Header().Set("Location", exportPath(r, rec))
+	writeJSON(w, http.StatusAccepted, exportResponse{Message: "Export requested", Export: toExportDetail(r, rec)})
+}
+
+// GET /users/{user_id}/exports/{export_id}（本人のみ）
+func (s *Server) handleUserExport(w http.ResponseWriter, r *http.Request, pathUserID, exportID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	rec, err := s.uc(r).GetExport(pathUserID, cred, exportID)
+	if err != nil {
+		s.writeExportError(w, err, "No export found")
+		return
+	}
+	writeJSON(w, http.StatusOK, exportResponse{Message: "Export status", Export: toExportDetail(r, rec)})
+}
+
+// GET /users/{user_id}/exports/{export_id}/archive（本人のみ）
+func (s *Server) handleUserExportArchive(w http.ResponseWriter, r *http.Request, pathUserID, exportID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	data, rec, err := s.uc(r).ExportArchive(pathUserID, cred, exportID)
+	if err != nil {
+		s.writeExportError(w, err, "No export found")
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="export-`+rec.ID+`.zip"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) writeExportError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, usecase.ErrExportNotReady):
+		writeJSON(w, http.StatusConflict, messageOnly{Message: "Export is not ready"})
+	case errors.Is(err, usecase.ErrExportExpired):
+		writeJSON(w, http.StatusGone, messageOnly{Message: "Export has expired"})
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for access")
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: notFound})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func exportPath(r *http.Request, rec *domain.ExportRecord) string {
+	return userPath(r, rec.UserID) + "/exports/" + url.PathEscape(rec.ID)
+}
+
+// POST /users/{user_id}/exports・GET /users/{user_id}/exports/{export_id} 出力
+type exportResponse struct {
+	Message string       `json:"message"`
+	Export  exportDetail `json:"export"`
+}
+
+type exportDetail struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	// status が ready の間のみ
+	DownloadURL string `json:"download_url,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	Size        int    `json:"size,omitempty"`
+}
+
+func toExportDetail(r *http.Request, rec *domain.ExportRecord) exportDetail {
+	d := exportDetail{
+		ID:          rec.ID,
+		Status:      string(rec.Status),
+		CreatedAt:   formatTime(rec.CreatedAt),
+		CompletedAt: formatTime(rec.CompletedAt),
+		ExpiresAt:   formatTime(rec.ExpiresAt),
+	}
+	if rec.Status == domain.ExportReady {
+		d.DownloadURL = exportPath(r, rec) + "/archive"
+		d.Size = rec.Size
+	}
+	return d
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"accountapi/internal/usecase"
+)
+
+// GET /users/{user_id}/followers・/following?limit=&cursor=&sort=（閲覧できるユーザーのみ）
+func (s *Server) handleUserFollows(w http.ResponseWriter, r *http.Request, pathUserID string, followers bool) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	limit, cursor, ok := pageParams(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Follow list retrieval failed", "Invalid limit or cursor"})
+		return
+	}
+	order, ok := usecase.ParseUserSort(r.URL.Query().Get("sort"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Follow list retrieval failed", "Invalid sort"})
+		return
+	}
+	var (
+		list    []*usecase.FollowEntry
+		next    uint64
+		err     error
+		message string
+	)
+	if followers {
+		list, next, err = s.uc(r).ListFollowers(pathUserID, cred, cursor, limit, order)
+		message = "Followers"
+	} else {
+		list, next, err = s.uc(r).ListFollowing(pathUserID, cred, cursor, limit, order)
+		message = "Following"
+	}
+	if err != nil {
+		s.writeRelationshipError(w, err, "Follow list retrieval failed")
+		return
+	}
+	resp := followListResponse{Message: message, Users: make([]followDetail, 0, len(list))}
+	for _, f := range list {
+		resp.Users = append(resp.Users, followDetail{UserID: f.UserID, FollowedAt: f.At.UTC().Format(time.RFC3339)})
+	}
+	if next != 0 {
+		resp.NextCursor = strconv.FormatUint(next, 10)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST/DELETE /users/{user_id}/following/{target}（本人のみ）
+func (s *Server) handleUserFollow(w http.ResponseWriter, r *http.Request, pathUserID, targetID string) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		if err := s.uc(r).Unfollow(pathUserID, cred, targetID); err != nil {
+			s.writeRelationshipError(w, err, "Unfollow failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, messageOnly{Message: "User successfully unfollowed"})
+		return
+	}
+	if err := s.uc(r).Follow(pathUserID, cred, targetID); err != nil {
+		s.writeRelationshipError(w, err, "Follow failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, messageOnly{Message: "User successfully followed"})
+}
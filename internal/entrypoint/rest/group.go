@@ -0,0 +1,292 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// POST /groups（作成したユーザーが owner になる）
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	req, ok := decodeGroupRequest(w, r)
+	if !ok {
+		writeGroupFailure(w, "Group creation failed", "Required name")
+		return
+	}
+	g, err := s.uc(r).CreateGroup(cred, req.Name)
+	if err != nil {
+		s.writeGroupError(w, err, "Group creation failed")
+		return
+	}
+	w.Header().Set("Location", groupPath(r, g.ID))
+	writeJSON(w, http.StatusCreated, groupResponse{Message: "Group successfully created", Group: toGroupDetail(g)})
+}
+
+// /groups/{group_id}・/groups/{group_id}/members/{user_id}[/accept]
+func (s *Server) handleGroup(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/groups/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		s.handleGroupResource(w, r, parts[0])
+	case len(parts) == 3 && parts[0] != "" && parts[1] == "members" && parts[2] != "":
+		s.handleGroupMember(w, r, parts[0], parts[2])
+	case len(parts) == 4 && parts[0] != "" && parts[1] == "members" && parts[2] != "" && parts[3] == "accept":
+		s.handleGroupInvitation(w, r, parts[0], parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GET（メンバーのみ）・PATCH・DELETE（owner のみ）/groups/{group_id}
+func (s *Server) handleGroupResource(w http.ResponseWriter, r *http.Request, groupID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPatch && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		g, err := s.uc(r).GetGroup(cred, groupID)
+		if err != nil {
+			s.writeGroupError(w, err, "Group retrieval failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, groupResponse{Message: "Group details", Group: toGroupDetail(g)})
+	case http.MethodPatch:
+		req, ok := decodeGroupRequest(w, r)
+		if !ok {
+			writeGroupFailure(w, "Group update failed", "Required name")
+			return
+		}
+		g, err := s.uc(r).RenameGroup(cred, groupID, req.Name)
+		if err != nil {
+			s.writeGroupError(w, err, "Group update failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, groupResponse{Message: "Group successfully updated", Group: toGroupDetail(g)})
+	case http.MethodDelete:
+		if err := s.uc(r).DeleteGroup(cred, groupID); err != nil {
+			s.writeGroupError(w, err, "Group deletion failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, messageOnly{Message: "Group successfully deleted"})
+	}
+}
+
+// PUT /groups/{group_id}/members/{user_id}（owner のみ、招待・役割の変更。{"role": "member" | "owner"}、body 省略時は member）
+// DELETE /groups/{group_id}/members/{user_id}（owner、または本人の脱退・招待の辞退）
+func (s *Server) handleGroupMember(w http.ResponseWriter, r *http.Request, groupID, userID string) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		if err := s.uc(r).RemoveGroupMember(cred, groupID, userID); err != nil {
+			s.writeGroupError(w, err, "Group membership update failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, messageOnly{Message: "Group member successfully removed"})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+	var req groupMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeGroupFailure(w, "Group membership update failed", "Invalid group role")
+		return
+	}
+	m, created, err := s.uc(r).SetGroupMember(cred, groupID, userID, domain.GroupRole(req.Role))
+	if err != nil {
+		s.writeGroupError(w, err, "Group membership update failed")
+		return
+	}
+	status, message := http.StatusOK, "Group member successfully updated"
+	if created {
+		status, message = http.StatusCreated, "Group member successfully invited"
+	}
+	writeJSON(w, status, groupMemberResponse{Message: message, Member: toGroupMemberDetail(m)})
+}
+
+// POST /groups/{group_id}/members/{user_id}/accept（本人のみ、招待の承諾）
+func (s *Server) handleGroupInvitation(w http.ResponseWriter, r *http.Request, groupID, userID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	m, err := s.uc(r).AcceptGroupInvitation(cred, groupID, userID)
+	if err != nil {
+		s.writeGroupError(w, err, "Group invitation acceptance failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, groupMemberResponse{Message: "Group invitation accepted", Member: toGroupMemberDetail(m)})
+}
+
+// GET /users/{user_id}/groups（本人はすべて、他のユーザーは閲覧者も参加しているグループのみ）
+func (s *Server) handleUserGroups(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	list, err := s.uc(r).ListUserGroups(pathUserID, cred)
+	if err != nil {
+		s.writeGroupError(w, err, "Group list retrieval failed")
+		return
+	}
+	resp := userGroupListResponse{Message: "Groups", Groups: make([]userGroupDetail, 0, len(list))}
+	for _, g := range list {
+		resp.Groups = append(resp.Groups, userGroupDetail{
+			ID:       g.ID,
+			Name:     g.Name,
+			Role:     string(g.Role),
+			JoinedAt: formatTime(g.JoinedAt),
+			Pending:  g.Pending,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func decodeGroupRequest(w http.ResponseWriter, r *http.Request) (groupRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+	var req groupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		return groupRequest{}, false
+	}
+	return req, true
+}
+
+func writeGroupFailure(w http.ResponseWriter, failure, cause string) {
+	writeJSON(w, http.StatusBadRequest, struct {
+		Message string `json:"message"`
+		Cause   string `json:"cause"`
+	}{failure, cause})
+}
+
+// writeGroupError: グループ関連の API のエラー応答。メンバーでないグループは存在しないものとして 404
+func (s *Server) writeGroupError(w http.ResponseWriter, err error, failure string) {
+	var vErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		writeJSON(w, http.StatusBadRequest, newValidationFailure(failure, vErr))
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for update")
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrGroupNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No group found"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func groupPath(r *http.Request, groupID string) string {
+	return basePath(r) + "/groups/" + url.PathEscape(groupID)
+}
+
+// POST /groups・PATCH /groups/{group_id} 入力
+type groupRequest struct {
+	Name string `json:"name"`
+}
+
+// PUT /groups/{group_id}/members/{user_id} 入力
+type groupMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// /groups/{group_id} 出力
+type groupResponse struct {
+	Message string      `json:"message"`
+	Group   groupDetail `json:"group"`
+}
+
+type groupDetail struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Role      string              `json:"role"` // 閲覧者の役割
+	CreatedAt string              `json:"created_at"`
+	UpdatedAt string              `json:"updated_at"`
+	Members   []groupMemberDetail `json:"members"`
+}
+
+type groupMemberResponse struct {
+	Message string            `json:"message"`
+	Member  groupMemberDetail `json:"member"`
+}
+
+type groupMemberDetail struct {
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+	Pending  bool   `json:"pending"` // 招待中
+}
+
+// GET /users/{user_id}/groups 出力
+type userGroupListResponse struct {
+	Message string            `json:"message"`
+	Groups  []userGroupDetail `json:"groups"`
+}
+
+type userGroupDetail struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+	Pending  bool   `json:"pending"` // 招待中
+}
+
+func toGroupDetail(g *usecase.GroupView) groupDetail {
+	d := groupDetail{
+		ID:        g.ID,
+		Name:      g.Name,
+		Role:      string(g.Role),
+		CreatedAt: formatTime(g.CreatedAt),
+		UpdatedAt: formatTime(g.UpdatedAt),
+		Members:   make([]groupMemberDetail, 0, len(g.Members)),
+	}
+	for _, m := range g.Members {
+		d.Members = append(d.Members, toGroupMemberDetail(m))
+	}
+	return d
+}
+
+func toGroupMemberDetail(m *domain.GroupMembership) groupMemberDetail {
+	return groupMemberDetail{UserID: m.UserID, Role: string(m.Role), JoinedAt: formatTime(m.JoinedAt), Pending: m.Pending}
+}
@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// GET /users/{user_id}/history?limit=&cursor=（本人のみ）
+func (s *Server) handleUserHistory(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	limit, cursor, ok := pageParams(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"History retrieval failed", "Invalid limit or cursor"})
+		return
+	}
+	revs, next, err := s.uc(r).ListProfileHistory(pathUserID, cred, cursor, limit)
+	if err != nil {
+		s.writeHistoryError(w, err, "History retrieval failed", "No user found")
+		return
+	}
+	resp := historyListResponse{Message: "Profile history", History: make([]revisionDetail, 0, len(revs))}
+	for _, rev := range revs {
+		resp.History = append(resp.History, revisionDetail{
+			Rev:      rev.Rev,
+			ActorID:  rev.ActorID,
+			At:       rev.At.UTC().Format(time.RFC3339),
+			Old:      toSnapshotDetail(rev.Old),
+			New:      toSnapshotDetail(rev.New),
+			RevertOf: rev.RevertOf,
+		})
+	}
+	if next != 0 {
+		resp.NextCursor = strconv.FormatUint(next, 10)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /users/{user_id}/history/{rev}/revert（本人のみ）
+// 版 rev の変更後の値を、通常の更新と同じ検証を経て新しい版として反映する
+func (s *Server) handleUserHistoryRevert(w http.ResponseWriter, r *http.Request, pathUserID, revParam string) {
+	rev, err := strconv.ParseUint(revParam, 10, 64)
+	if err != nil || rev == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	u, err := s.uc(r).RevertProfile(pathUserID, cred, rev)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPendingReview) {
+			writeJSON(w, http.StatusAccepted, messageOnly{Message: "User update is pending review"})
+			return
+		}
+		s.writeHistoryError(w, err, "Revert failed", "No revision found")
+		return
+	}
+	writeJSON(w, http.StatusOK, userResponse{Message: "Profile successfully reverted", User: toUserDetail(r, u, true)})
+}
+
+func toSnapshotDetail(p domain.ProfileSnapshot) snapshotDetail {
+	return snapshotDetail{Nickname: p.Nickname, Comment: p.Comment, Attributes: p.Attributes}
+}
+
+func (s *Server) writeHistoryError(w http.ResponseWriter, err error, failure, notFound string) {
+	var vErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		writeJSON(w, http.StatusBadRequest, newValidationFailure(failure, vErr))
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for access")
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: notFound})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,208 @@
+package rest
+
+import (
+	"net/http"
+	"reflect"
+
+	"golang.org/x/text/language"
+)
+
+// レスポンスの message・cause の言語。Accept-Language で決め、既定は英語（従来どおりの文言）
+var (
+	supportedLanguages = []language.Tag{language.English, language.Japanese}
+	languageMatcher    = language.NewMatcher(supportedLanguages)
+)
+
+// messageCatalog: 英語の文言 → 各言語の文言。無いものは英語のまま返す
+var messageCatalog = map[language.Tag]map[string]string{
+	language.Japanese: messagesJa,
+}
+
+var messagesJa = map[string]string{
+	"ok": "ok",
+
+	// 成功
+	"Account activity":                           "アカウントの利用履歴",
+	"Account and user successfully removed":      "アカウントとユーザーを削除しました",
+	"Account successfully deactivated":           "アカウントの利用を停止しました",
+	"Account successfully reactivated":           "アカウントの利用を再開しました",
+	"Account successfully created":               "アカウントを作成しました",
+	"Active sessions":                            "有効なセッション",
+	"All sessions successfully revoked":          "すべてのセッションを無効にしました",
+	"Avatar successfully removed":                "アバターを削除しました",
+	"Avatar successfully updated":                "アバターを更新しました",
+	"Blocked users":                              "ブロック中のユーザー",
+	"Email already verified":                     "メールアドレスは確認済みです",
+	"Email successfully removed":                 "メールアドレスを削除しました",
+	"Email successfully verified":                "メールアドレスを確認しました",
+	"Export requested":                           "個人データの書き出しを受け付けました",
+	"Export status":                              "個人データの書き出しの状態",
+	"Followers":                                  "フォロワー",
+	"Group details":                              "グループの情報",
+	"Group invitation accepted":                  "グループへの招待を承諾しました",
+	"Group member successfully invited":          "グループにメンバーを招待しました",
+	"Group member successfully removed":          "グループからメンバーを外しました",
+	"Group member successfully updated":          "グループのメンバーの役割を変更しました",
+	"Group successfully created":                 "グループを作成しました",
+	"Group successfully deleted":                 "グループを削除しました",
+	"Group successfully updated":                 "グループを更新しました",
+	"Groups":                                     "参加しているグループ",
+	"Following":                                  "フォロー中",
+	"Impersonation session successfully created": "なりすましセッションを作成しました",
+	"Nickname availability":                      "ニックネームの利用可否",
+	"Pending profile changes":                    "審査待ちのプロフィール変更",
+	"Privacy settings":                           "公開範囲の設定",
+	"Privacy settings successfully updated":      "公開範囲の設定を更新しました",
+	"Profile change approved":                    "プロフィールの変更を承認しました",
+	"Profile change rejected":                    "プロフィールの変更を却下しました",
+	"Profile history":                            "プロフィールの変更履歴",
+	"Profile successfully reverted":              "プロフィールを元に戻しました",
+	"Session successfully created":               "セッションを作成しました",
+	"Session successfully revoked":               "セッションを無効にしました",
+	"User ID successfully changed":               "user_id を変更しました",
+	"User details by user_id":                    "ユーザー情報",
+	"User successfully blocked":                  "ユーザーをブロックしました",
+	"User successfully followed":                 "ユーザーをフォローしました",
+	"User successfully unblocked":                "ユーザーのブロックを解除しました",
+	"User successfully unfollowed":               "ユーザーのフォローを解除しました",
+	"User successfully updated":                  "ユーザー情報を更新しました",
+	"User update is pending review":              "ユーザー情報の更新は審査待ちです",
+	"Verification email sent":                    "確認メールを送信しました",
+
+	// 失敗
+	"Account creation failed":            "アカウントの作成に失敗しました",
+	"Activity retrieval failed":          "利用履歴の取得に失敗しました",
+	"Authentication failed":              "認証に失敗しました",
+	"Avatar upload failed":               "アバターのアップロードに失敗しました",
+	"Block failed":                       "ブロックに失敗しました",
+	"Block list retrieval failed":        "ブロック一覧の取得に失敗しました",
+	"Email update failed":                "メールアドレスの更新に失敗しました",
+	"Email verification failed":          "メールアドレスの確認に失敗しました",
+	"Export has expired":                 "書き出したデータのダウンロード期限が切れています",
+	"Export is not ready":                "個人データを書き出し中です",
+	"Follow failed":                      "フォローに失敗しました",
+	"Follow list retrieval failed":       "フォロー一覧の取得に失敗しました",
+	"Group creation failed":              "グループの作成に失敗しました",
+	"Group deletion failed":              "グループの削除に失敗しました",
+	"Group invitation acceptance failed": "グループへの招待の承諾に失敗しました",
+	"Group list retrieval failed":        "グループ一覧の取得に失敗しました",
+	"Group membership update failed":     "グループのメンバーの更新に失敗しました",
+	"Group retrieval failed":             "グループの取得に失敗しました",
+	"Group update failed":                "グループの更新に失敗しました",
+	"History retrieval failed":           "変更履歴の取得に失敗しました",
+	"Identicon generation failed":        "アイデンティコンの生成に失敗しました",
+	"Impersonation failed":               "なりすましに失敗しました",
+	"Moderation failed":                  "審査に失敗しました",
+	"Moderation queue retrieval failed":  "審査待ち一覧の取得に失敗しました",
+	"No avatar found":                    "アバターが見つかりません",
+	"No export found":                    "書き出しが見つかりません",
+	"No group found":                     "グループが見つかりません",
+	"No pending change found":            "審査待ちの変更が見つかりません",
+	"No permission for access":           "アクセスする権限がありません",
+	"No permission for close":            "削除する権限がありません",
+	"No permission for impersonation":    "なりすましの権限がありません",
+	"No permission for moderation":       "審査の権限がありません",
+	"No permission for update":           "更新する権限がありません",
+	"No revision found":                  "変更履歴が見つかりません",
+	"No tenant found":                    "テナントが見つかりません",
+	"No session found":                   "セッションが見つかりません",
+	"No user found":                      "ユーザーが見つかりません",
+	"Account is deactivated":             "アカウントは利用停止中です",
+	"Not permitted while impersonating":  "なりすまし中は実行できません",
+	"Privacy retrieval failed":           "公開範囲の取得に失敗しました",
+	"Privacy update failed":              "公開範囲の更新に失敗しました",
+	"Revert failed":                      "元に戻せませんでした",
+	"Server is busy":                     "サーバーが混雑しています",
+	"Too many verification emails":       "確認メールの送信間隔が短すぎます。しばらくしてから再試行してください",
+	"Unblock failed":                     "ブロックの解除に失敗しました",
+	"Unfollow failed":                    "フォローの解除に失敗しました",
+	"User ID change failed":              "user_id の変更に失敗しました",
+	"User updation failed":               "ユーザー情報の更新に失敗しました",
+
+	// cause
+	"Already same email is used":                                       "同じメールアドレスが既に使われています",
+	"Already same nickname is used":                                    "同じニックネームが既に使われています",
+	"Already same user_id is used":                                     "同じ user_id が既に使われています",
+	"Cannot block yourself":                                            "自分自身はブロックできません",
+	"Cannot follow yourself":                                           "自分自身はフォローできません",
+	"Containing prohibited words":                                      "禁止されている語句が含まれています",
+	"Group must keep at least one owner":                               "グループには owner が 1 人以上必要です",
+	"Group name must be 1 to 50 characters without control characters": "グループ名は制御文字を含まない 1〜50 文字で指定してください",
+	"Image dimensions exceed limit":                                    "画像の縦横のサイズが上限を超えています",
+	"Image exceeds size limit":                                         "画像のファイルサイズが上限を超えています",
+	"Incorrect character pattern":                                      "使用できない文字が含まれています",
+	"Input length is incorrect":                                        "入力の長さが正しくありません",
+	"Invalid group role":                                               "role が正しくありません",
+	"Invalid email address":                                            "メールアドレスが正しくありません",
+	"Invalid limit":                                                    "limit が正しくありません",
+	"Invalid limit or cursor":                                          "limit または cursor が正しくありません",
+	"Invalid sort":                                                     "sort が正しくありません",
+	"Invalid or expired verification token":                            "確認トークンが正しくないか、期限が切れています",
+	"Invalid visibility or field":                                      "公開範囲または項目が正しくありません",
+	"Nickname is confusable with another user":                         "ニックネームが他のユーザーと紛らわしいです",
+	"Nickname is reserved":                                             "このニックネームは予約されています",
+	"Not updatable user_id and password":                               "user_id と password は更新できません",
+	"Profile attribute does not match its schema":                      "プロフィール項目の値が定義に合っていません",
+	"Required avatar image":                                            "アバター画像を指定してください",
+	"Required name":                                                    "name を指定してください",
+	"Required email":                                                   "email を指定してください",
+	"Required nickname or comment":                                     "nickname または comment を指定してください",
+	"Required profile attribute is missing":                            "必須のプロフィール項目がありません",
+	"Required reason (up to 200 characters)":                           "reason を 200 文字以内で指定してください",
+	"Required user_id":                                                 "user_id を指定してください",
+	"Required user_id and password":                                    "user_id と password を指定してください",
+	"Required user_id and reason":                                      "user_id と reason を指定してください",
+	"Size must be between 16 and 1024":                                 "サイズは 16 以上 1024 以下で指定してください",
+	"String length limit exceeded or containing invalid characters":    "文字数の上限を超えているか、使用できない文字が含まれています",
+	"Unknown profile attribute":                                        "未定義のプロフィール項目です",
+	"Unsupported image format (PNG, JPEG or GIF)":                      "対応していない画像形式です（PNG・JPEG・GIF）",
+	"Validation failed":                                                "入力内容が正しくありません",
+}
+
+// localizedWriter: リクエストごとに決めた言語を writeJSON に渡す
+type localizedWriter struct {
+	http.ResponseWriter
+	lang language.Tag
+}
+
+func (w *localizedWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func negotiateLanguage(r *http.Request) language.Tag {
+	tags, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	_, i, _ := languageMatcher.Match(tags...)
+	return supportedLanguages[i]
+}
+
+func withLanguage(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	w.Header().Add("Vary", "Accept-Language")
+	return &localizedWriter{ResponseWriter: w, lang: negotiateLanguage(r)}
+}
+
+// localize: v の message・cause を w の言語に置き換える。英語・カタログに無い文言はそのまま
+func localize(w http.ResponseWriter, v any) any {
+	lw, ok := w.(*localizedWriter)
+	if !ok {
+		return v
+	}
+	w.Header().Set("Content-Language", lw.lang.String())
+	catalog := messageCatalog[lw.lang]
+	if catalog == nil {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Struct {
+		return v
+	}
+	out := reflect.New(rv.Type()).Elem()
+	out.Set(rv)
+	for _, name := range []string{"Message", "Cause"} {
+		f := out.FieldByName(name)
+		if !f.IsValid() || f.Kind() != reflect.String || !f.CanSet() {
+			continue
+		}
+		if s, ok := catalog[f.String()]; ok {
+			f.SetString(s)
+		}
+	}
+	return out.Interface()
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// responseText: 応答の message・cause になる英語の文言（大文字で始まる、空白を含む文）
+var responseText = regexp.MustCompile(`^[A-Z][a-z]*( [^%\n]+)+$`)
+
+// responseLiterals: パッケージ内のソースから、応答に使われる文言のリテラルを集める。
+// ログやエラー値の文言（log.*・fmt.*・errors.New の引数）とヘッダーの値は応答の本文に出ないので除く
+func responseLiterals(t *testing.T) map[string]token.Position {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	found := map[string]token.Position{}
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") || name == "i18n.go" {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+					if pkg, ok := sel.X.(*ast.Ident); ok && (pkg.Name == "log" || pkg.Name == "fmt" || pkg.Name == "errors") {
+						return false
+					}
+					if recv, ok := sel.X.(*ast.CallExpr); ok {
+						if h, ok := recv.Fun.(*ast.SelectorExpr); ok && h.Sel.Name == "Header" {
+							return false
+						}
+					}
+				}
+			}
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			s, err := strconv.Unquote(lit.Value)
+			if err == nil && responseText.MatchString(s) {
+				found[s] = fset.Position(lit.Pos())
+			}
+			return true
+		})
+	}
+	return found
+}
+
+func TestEveryResponseMessageHasJapanese(t *testing.T) {
+	found := responseLiterals(t)
+	// 取りこぼしていないことの確認（messageOnly・validationFailure・validationCause の文言）
+	for _, s := range []string{"No user found", "Account creation failed", "Already same email is used"} {
+		if _, ok := found[s]; !ok {
+			t.Fatalf("%q was not collected from the sources", s)
+		}
+	}
+	for s, pos := range found {
+		if _, ok := messagesJa[s]; !ok {
+			t.Errorf("%s: %q has no Japanese entry in messagesJa", pos, s)
+		}
+	}
+}
+
+func signupFailure(t *testing.T, acceptLanguage string) (*httptest.ResponseRecorder, validationFailure) {
+	t.Helper()
+	s := New(Config{BlobDir: t.TempDir()})
+	r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader("{}"))
+	if acceptLanguage != "" {
+		r.Header.Set("Accept-Language", acceptLanguage)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	var body validationFailure
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return w, body
+}
+
+func TestResponseInJapaneseForJaJP(t *testing.T) {
+	w, body := signupFailure(t, "ja-JP,ja;q=0.9,en;q=0.8")
+	if got := w.Header().Get("Content-Language"); got != "ja" {
+		t.Errorf("Content-Language = %q, want ja", got)
+	}
+	if got := w.Header().Values("Vary"); !slices.Contains(got, "Accept-Language") {
+		t.Errorf("Vary = %q, want Accept-Language", got)
+	}
+	if body.Message != messagesJa["Account creation failed"] || body.Cause != messagesJa["Required user_id and password"] {
+		t.Errorf("body = %+v, want the Japanese message and cause", body)
+	}
+}
+
+func TestResponseFallsBackToEnglish(t *testing.T) {
+	w, body := signupFailure(t, "fr-FR,de;q=0.8")
+	if got := w.Header().Get("Content-Language"); got != "en" {
+		t.Errorf("Content-Language = %q, want en", got)
+	}
+	if body.Message != "Account creation failed" || body.Cause != "Required user_id and password" {
+		t.Errorf("body = %+v, want the English message and cause", body)
+	}
+}
@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"container/list"
+	"sync"
+)
+
+// identiconCacheSize: 保持する identicon の数。1024px の PNG でも 1 KiB 未満のため、数 MiB に収まる
+const identiconCacheSize = 4096
+
+// identiconCache: 生成した identicon の LRU。認証なしで誰でも要求できるため、同じものを作り直さない
+type identiconCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[identiconKey]*list.Element
+	order   *list.List // 先頭が最近使ったもの
+}
+
+type identiconKey struct {
+	userID string
+	size   int
+	svg    bool
+}
+
+type identiconEntry struct {
+	key  identiconKey
+	data []byte
+	etag string
+}
+
+func newIdenticonCache(max int) *identiconCache {
+	return &identiconCache{max: max, entries: make(map[identiconKey]*list.Element), order: list.New()}
+}
+
+func (c *identiconCache) get(key identiconKey) (*identiconEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*identiconEntry), true
+}
+
+func (c *identiconCache) add(e *identiconEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[e.key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[e.key] = c.order.PushFront(e)
+	if c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*identiconEntry).key)
+	}
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdenticonCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newIdenticonCache(2)
+	a, b, d := identiconKey{userID: "a"}, identiconKey{userID: "b"}, identiconKey{userID: "d"}
+	c.add(&identiconEntry{key: a})
+	c.add(&identiconEntry{key: b})
+	c.get(a)
+	c.add(&identiconEntry{key: d})
+	if _, ok := c.get(b); ok {
+		t.Error("b was kept, want it evicted as least recently used")
+	}
+	for _, key := range []identiconKey{a, d} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("%s was evicted", key.userID)
+		}
+	}
+}
+
+// busyExecutor: 常に混雑している usecase.Executor
+type busyExecutor struct{}
+
+func (busyExecutor) Do(fn func()) error { return errors.New("saturated") }
+
+func TestIdenticonServedFromCache(t *testing.T) {
+	s := New(Config{BlobDir: t.TempDir()})
+	get := func(etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/users/TaroYamada/identicon.png?size=1024", nil)
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w
+	}
+	first := get("")
+	if first.Code != http.StatusOK || first.Header().Get("ETag") == "" || first.Header().Get("Cache-Control") == "" {
+		t.Fatalf("status = %d, headers = %v", first.Code, first.Header())
+	}
+
+	// 2 回目以降は生成しない（画像の処理が混雑していても返せる）
+	s.UC.ImageProcessing = busyExecutor{}
+	second := get("")
+	if second.Code != http.StatusOK || second.Body.String() != first.Body.String() {
+		t.Errorf("cached: status = %d, want the same image", second.Code)
+	}
+	if w := get(first.Header().Get("ETag")); w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: status = %d, want 304", w.Code)
+	}
+
+	// 未生成のものは混雑時に 503
+	r := httptest.NewRequest(http.MethodGet, "/users/HanakoSato/identicon.png", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("busy: status = %d, want 503 with Retry-After", w.Code)
+	}
+}
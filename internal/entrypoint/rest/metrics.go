@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"accountapi/internal/infrastructure/hashpool"
+)
+
+// GET /metrics（Prometheus テキスト形式）
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	st := s.hashPool.Stats()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	fmt.Fprintln(w, "# HELP accountapi_hash_queue_wait_seconds Time spent waiting for a bcrypt worker.")
+	fmt.Fprintln(w, "# TYPE accountapi_hash_queue_wait_seconds histogram")
+	for i, le := range hashpool.WaitBuckets {
+		fmt.Fprintf(w, "accountapi_hash_queue_wait_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(le, 'g', -1, 64), st.WaitBuckets[i])
+	}
+	fmt.Fprintf(w, "accountapi_hash_queue_wait_seconds_bucket{le=\"+Inf\"} %d\n", st.Completed)
+	fmt.Fprintf(w, "accountapi_hash_queue_wait_seconds_sum %g\n", st.WaitSum.Seconds())
+	fmt.Fprintf(w, "accountapi_hash_queue_wait_seconds_count %d\n", st.Completed)
+
+	fmt.Fprintln(w, "# HELP accountapi_hash_queue_wait_max_seconds Longest observed wait for a bcrypt worker.")
+	fmt.Fprintln(w, "# TYPE accountapi_hash_queue_wait_max_seconds gauge")
+	fmt.Fprintf(w, "accountapi_hash_queue_wait_max_seconds %g\n", st.WaitMax.Seconds())
+
+	fmt.Fprintln(w, "# HELP accountapi_hash_rejected_total bcrypt operations shed because the pool was saturated.")
+	fmt.Fprintln(w, "# TYPE accountapi_hash_rejected_total counter")
+	fmt.Fprintf(w, "accountapi_hash_rejected_total %d\n", st.Rejected)
+
+	fmt.Fprintln(w, "# HELP accountapi_hash_in_flight bcrypt operations currently running.")
+	fmt.Fprintln(w, "# TYPE accountapi_hash_in_flight gauge")
+	fmt.Fprintf(w, "accountapi_hash_in_flight %d\n", st.InFlight)
+
+	fmt.Fprintln(w, "# HELP accountapi_hash_queued Callers waiting for a bcrypt worker.")
+	fmt.Fprintln(w, "# TYPE accountapi_hash_queued gauge")
+	fmt.Fprintf(w, "accountapi_hash_queued %d\n", st.Queued)
+}
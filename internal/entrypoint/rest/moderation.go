@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"accountapi/internal/usecase"
+)
+
+// GET /admin/moderation?limit=（管理者のみ）
+// 審査待ちのプロフィール変更を古い順に返す
+func (s *Server) handleModerationQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeJSON(w, http.StatusBadRequest, struct {
+				Message string `json:"message"`
+				Cause   string `json:"cause"`
+			}{"Moderation queue retrieval failed", "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+	list, err := s.uc(r).ListModeration(cred, limit)
+	if err != nil {
+		s.writeModerationError(w, err, "Moderation queue retrieval failed")
+		return
+	}
+	resp := moderationListResponse{Message: "Pending profile changes", Pending: make([]moderationDetail, 0, len(list))}
+	for _, m := range list {
+		resp.Pending = append(resp.Pending, moderationDetail{
+			ID:         m.ID,
+			UserID:     m.UserID,
+			Nickname:   m.Update.Nickname,
+			Comment:    m.Update.Comment,
+			Attributes: m.Update.Attributes,
+			Matches:    m.Matches,
+			CreatedAt:  m.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /admin/moderation/{id}/approve|reject（管理者のみ）
+func (s *Server) handleModerationDecision(w http.ResponseWriter, r *http.Request) {
+	id, decision, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/admin/moderation/"), "/")
+	if !found || id == "" || (decision != "approve" && decision != "reject") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	approve := decision == "approve"
+	u, err := s.uc(r).DecideModeration(cred, id, approve)
+	if err != nil {
+		s.writeModerationError(w, err, "Moderation failed")
+		return
+	}
+	message := "Profile change rejected"
+	if approve {
+		message = "Profile change approved"
+	}
+	writeJSON(w, http.StatusOK, userResponse{Message: message, User: toUserDetail(r, u, false)})
+}
+
+func (s *Server) writeModerationError(w http.ResponseWriter, err error, message string) {
+	var vErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		// 承認時に現在の設定で検証し直した結果
+		writeJSON(w, http.StatusBadRequest, newValidationFailure(message, vErr))
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeJSON(w, http.StatusForbidden, messageOnly{Message: "No permission for moderation"})
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No pending change found"})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"accountapi/internal/usecase"
+)
+
+// GET /nicknames/{nickname}/availability（要認証）
+func (s *Server) handleNicknames(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.EscapedPath(), "/nicknames/")
+	name, sub, found := strings.Cut(rest, "/")
+	if !found || sub != "availability" || name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	nickname, err := url.PathUnescape(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	a, err := s.uc(r).CheckNicknameAvailability(cred, nickname)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrAuthFailed):
+			writeAuthFailed(w)
+		case errors.Is(err, usecase.ErrBusy):
+			s.writeBusy(w)
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	resp := nicknameAvailabilityResponse{Message: "Nickname availability", Nickname: a.Nickname, Available: a.Available}
+	if !a.Available {
+		resp.Cause = validationCause(a.Reason)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// GET/PUT /users/{user_id}/privacy（本人のみ）
+func (s *Server) handleUserPrivacy(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	if r.Method == http.MethodGet {
+		p, err := s.uc(r).GetPrivacy(pathUserID, cred)
+		if err != nil {
+			s.writePrivacyError(w, err, "Privacy retrieval failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, privacyResponse{Message: "Privacy settings", Privacy: toPrivacyDetail(p)})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+	var req privacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"Privacy update failed", validationCause(usecase.ValidationReasonPrivacyInvalid)})
+		return
+	}
+	fields := make(map[string]domain.Visibility, len(req.Fields))
+	for name, v := range req.Fields {
+		fields[name] = domain.Visibility(v)
+	}
+	p, err := s.uc(r).SetPrivacy(pathUserID, cred, domain.Visibility(req.Profile), fields)
+	if err != nil {
+		s.writePrivacyError(w, err, "Privacy update failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, privacyResponse{Message: "Privacy settings successfully updated", Privacy: toPrivacyDetail(p)})
+}
+
+func toPrivacyDetail(p domain.PrivacySettings) privacyDetail {
+	d := privacyDetail{Profile: string(p.ProfileVisibility()), Fields: make(map[string]string, len(p.Fields))}
+	for name, v := range p.Fields {
+		d.Fields[name] = string(v)
+	}
+	return d
+}
+
+func (s *Server) writePrivacyError(w http.ResponseWriter, err error, failure string) {
+	var vErr *usecase.ValidationError
+	switch {
+	case errors.As(err, &vErr):
+		writeJSON(w, http.StatusBadRequest, newValidationFailure(failure, vErr))
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for access")
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
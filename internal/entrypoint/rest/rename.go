@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"accountapi/internal/usecase"
+)
+
+// PUT /users/{user_id}/user_id（本人のみ）。旧 user_id は一定期間 GET /users/{旧 user_id} から 301 で転送される
+func (s *Server) handleUserRename(w http.ResponseWriter, r *http.Request, pathUserID string) {
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	defer r.Body.Close()
+	var req renameUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		writeJSON(w, http.StatusBadRequest, struct {
+			Message string `json:"message"`
+			Cause   string `json:"cause"`
+		}{"User ID change failed", "Required user_id"})
+		return
+	}
+	u, err := s.uc(r).RenameUser(pathUserID, cred, req.UserID)
+	if err != nil {
+		var vErr *usecase.ValidationError
+		switch {
+		case errors.As(err, &vErr):
+			writeJSON(w, http.StatusBadRequest, newValidationFailure("User ID change failed", vErr))
+		case errors.Is(err, usecase.ErrNoPerm):
+			writeForbidden(w, err, "No permission for update")
+		case errors.Is(err, usecase.ErrAuthFailed):
+			writeAuthFailed(w)
+		case errors.Is(err, usecase.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
+		case errors.Is(err, usecase.ErrBusy):
+			s.writeBusy(w)
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Location", userPath(r, u.UserID))
+	writeJSON(w, http.StatusOK, userResponse{Message: "User ID successfully changed", User: toUserDetail(r, u, true)})
+}
+
+// writeMoved: 旧 user_id への GET を変更後の user_id へ転送する
+func writeMoved(w http.ResponseWriter, r *http.Request, userID string) {
+	http.Redirect(w, r, userPath(r, userID), http.StatusMovedPermanently)
+}
@@ -2,11 +2,17 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+
+	"accountapi/internal/usecase"
 )
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	v = localize(w, v)
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
@@ -15,3 +21,24 @@ func writeAuthFailed(w http.ResponseWriter) {
 	w.Header().Set("WWW-Authenticate", `Basic realm="account-api"`)
 	writeJSON(w, http.StatusUnauthorized, messageOnly{Message: "Authentication failed"})
 }
+
+// writeForbidden: 403。なりすまし中・利用停止中に禁止された操作はその旨を返す
+func writeForbidden(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, usecase.ErrImpersonationForbidden):
+		message = "Not permitted while impersonating"
+	case errors.Is(err, usecase.ErrDeactivated):
+		message = "Account is deactivated"
+	}
+	writeJSON(w, http.StatusForbidden, messageOnly{Message: message})
+}
+
+// 混雑時の 503。待ち時間の上限を目安に再試行を促す
+func (s *Server) writeBusy(w http.ResponseWriter) {
+	retry := int(math.Ceil(s.hashPool.Config().WaitTimeout.Seconds()))
+	if retry < 1 {
+		retry = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retry))
+	writeJSON(w, http.StatusServiceUnavailable, messageOnly{Message: "Server is busy"})
+}
@@ -3,33 +3,216 @@ package rest
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"net/http"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"accountapi/internal/domain"
+	"accountapi/internal/infrastructure/blobstore/localfs"
+	"accountapi/internal/infrastructure/export"
+	"accountapi/internal/infrastructure/hashpool"
+	"accountapi/internal/infrastructure/mailer"
 	"accountapi/internal/infrastructure/repository/memrepo"
 	"accountapi/internal/usecase"
 )
 
 type Server struct {
-	UC  *usecase.Usecase
-	mux *http.ServeMux
+	// UC: テナントを使わない場合の Usecase。テナントごとのものは tenants（リクエストからは uc(r)）
+	UC                *usecase.Usecase
+	mux               *http.ServeMux
+	hashPool          *hashpool.Pool
+	identicons        *identiconCache
+	trustProxyHeaders bool
+	certUsers         CertUserMap
+	tenantResolution  TenantResolution
+	tenants           map[string]*usecase.Usecase
+	// adminAccounts: テナント名（domain.DefaultTenant を含む）→ 作成する管理者のアカウント
+	adminAccounts map[string]map[string]string
 }
 
-func New() *Server {
+// Config: サーバーの動作設定（cmd/api-server が環境変数から組み立てる）
+type Config struct {
+	// ConcealExistingUsers: /signup で user_id の使用有無を応答から判別できないようにする
+	ConcealExistingUsers bool
+	// bcrypt 実行プールの同時実行数・待ち行列の長さ・待ち時間の上限（0 は既定値）
+	HashConcurrency int
+	HashQueueDepth  int
+	HashWaitTimeout time.Duration
+	// ImageConcurrency: アバター画像の処理の同時実行数（0 は既定値 DefaultImageConcurrency）
+	ImageConcurrency int
+	// SessionTTL: セッショントークンの有効期間（0 は既定値）
+	SessionTTL time.Duration
+	// TrustProxyHeaders: X-Forwarded-For を接続元 IP として扱う（リバースプロキシ配下で有効にする）
+	TrustProxyHeaders bool
+	// MailDir: 送信メールを .eml として書き出すディレクトリ。未指定の場合は配送せずに捨てる（宛先と件名のみログに出す）
+	MailDir string
+	// EmailVerificationTTL: メールアドレス確認トークンの有効期間（0 は既定値）
+	EmailVerificationTTL time.Duration
+	// EmailResendInterval: 確認メールを再び送れるまでの間隔（0 は既定値）
+	EmailResendInterval time.Duration
+	// 利用履歴の保持上限（ユーザーごとの件数・期間）
+	ActivityMaxEvents int
+	ActivityRetention time.Duration
+	// CertUsers: 検証済みクライアント証明書から user_id への対応表。空の場合は証明書による認証を行わない
+	CertUsers CertUserMap
+	// Admins: 管理者の user_id（テナントを使う場合は各テナントの TenantPolicy.Admins のみ）
+	Admins []string
+	// AdminAccounts: Admins のアカウントの user_id → パスワードハッシュ（bcrypt）。ProvisionAdmins で作成する
+	AdminAccounts map[string]string
+	// ImpersonationTTL: なりすましセッションの有効期間（0 は既定値）
+	ImpersonationTTL time.Duration
+	// BlobDir: アバター画像などを保存するディレクトリ
+	BlobDir string
+	// ProfileSchema: カスタムプロフィール項目の定義（nil は項目なし）
+	ProfileSchema *domain.ProfileSchema
+	// Moderator: nickname・comment の内容審査（nil は審査しない）
+	Moderator usecase.Moderator
+	// RejectConfusableNicknames: 他ユーザーの表示名と紛らわしい nickname を拒否する
+	RejectConfusableNicknames bool
+	// UniqueNicknames: nickname の重複を許さない
+	UniqueNicknames bool
+	// ReservedNicknames: 誰も使えない nickname
+	ReservedNicknames []string
+	// UserIDAliasTTL: user_id の変更後、旧 user_id を別名として残す期間（0 は既定値）
+	UserIDAliasTTL time.Duration
+	// RenameListeners: user_id の変更を通知する先（全テナント共通。UserRenamed.Tenant で区別する）
+	RenameListeners []usecase.RenameListener
+	// ExportTTL: 書き出した個人データをダウンロードできる期間（0 は既定値）
+	ExportTTL time.Duration
+	// TenantResolution: テナントの決め方。TenantNone 以外では Tenants に定義したテナントのみ受け付ける
+	TenantResolution TenantResolution
+	// Tenants: テナント名 → テナントごとの設定
+	Tenants map[string]TenantPolicy
+}
+
+// DefaultImageConcurrency: ImageConcurrency 未指定時のアバター画像の処理の同時実行数
+const DefaultImageConcurrency = 2
+
+func New(cfg Config) *Server {
 	repo := memrepo.New()
-	uc := &usecase.Usecase{Repo: repo}
-	s := &Server{UC: uc, mux: http.NewServeMux()}
+	pool := hashpool.New(hashpool.Config{
+		Concurrency: cfg.HashConcurrency,
+		QueueDepth:  cfg.HashQueueDepth,
+		WaitTimeout: cfg.HashWaitTimeout,
+	})
+	// 画像 1 枚の展開に数十 MiB を使うため、bcrypt とは別に少数に抑える。待ち時間は bcrypt と同じ
+	imageConcurrency := cfg.ImageConcurrency
+	if imageConcurrency <= 0 {
+		imageConcurrency = DefaultImageConcurrency
+	}
+	images := hashpool.New(hashpool.Config{
+		Concurrency: imageConcurrency,
+		WaitTimeout: pool.Config().WaitTimeout,
+	})
+	mail := newMailer(cfg.MailDir)
+	s := &Server{
+		UC:                newUsecase(cfg, repo, pool, images, mail, domain.DefaultTenant, cfg.BlobDir),
+		adminAccounts:     map[string]map[string]string{domain.DefaultTenant: cfg.AdminAccounts},
+		mux:               http.NewServeMux(),
+		hashPool:          pool,
+		identicons:        newIdenticonCache(identiconCacheSize),
+		trustProxyHeaders: cfg.TrustProxyHeaders,
+		certUsers:         cfg.CertUsers,
+		tenantResolution:  cfg.TenantResolution,
+	}
+	if cfg.TenantResolution != TenantNone {
+		s.tenants = make(map[string]*usecase.Usecase, len(cfg.Tenants))
+		for name, policy := range cfg.Tenants {
+			uc := newUsecase(cfg, repo, pool, images, mail, name, filepath.Join(cfg.BlobDir, "tenants", name))
+			policy.apply(uc)
+			s.tenants[name] = uc
+			s.adminAccounts[name] = policy.AdminAccounts
+		}
+	}
 	s.routes()
 	return s
 }
 
+// usecases: テナントを使わない場合のものと、テナント名順のテナントごとの Usecase
+func (s *Server) usecases() []*usecase.Usecase {
+	ucs := []*usecase.Usecase{s.UC}
+	for _, name := range slices.Sorted(maps.Keys(s.tenants)) {
+		ucs = append(ucs, s.tenants[name])
+	}
+	return ucs
+}
+
+// ProvisionAdmins: 設定（Config.AdminAccounts・TenantPolicy.AdminAccounts）の管理者のアカウントを作成する（既に存在すれば何もしない）
+func (s *Server) ProvisionAdmins() error {
+	for _, uc := range s.usecases() {
+		accounts := s.adminAccounts[uc.Tenant]
+		for _, id := range slices.Sorted(maps.Keys(accounts)) {
+			if err := uc.ProvisionAdmin(id, accounts[id]); err != nil {
+				return fmt.Errorf("tenant %q: %w", uc.Tenant, err)
+			}
+		}
+	}
+	return nil
+}
+
+// newUsecase: tenant の Usecase。ユーザーのレコードは repo をテナントで分けて共有し、
+// セッション・利用履歴・関係などのストアと blobDir はテナントごとに分ける
+func newUsecase(cfg Config, repo domain.UserRepository, pool, images *hashpool.Pool, mail usecase.Mailer, tenant, blobDir string) *usecase.Usecase {
+	return &usecase.Usecase{
+		Repo:                      repo,
+		Tenant:                    tenant,
+		ConcealExistingUsers:      cfg.ConcealExistingUsers,
+		Hashing:                   pool,
+		ImageProcessing:           images,
+		Sessions:                  memrepo.NewSessionRepo(),
+		SessionTTL:                cfg.SessionTTL,
+		Mailer:                    mail,
+		EmailVerificationTTL:      cfg.EmailVerificationTTL,
+		EmailResendInterval:       cfg.EmailResendInterval,
+		Activity:                  memrepo.NewActivityRepo(cfg.ActivityMaxEvents, cfg.ActivityRetention),
+		Admins:                    cfg.Admins,
+		ImpersonationTTL:          cfg.ImpersonationTTL,
+		Blobs:                     localfs.New(blobDir),
+		ProfileSchema:             cfg.ProfileSchema,
+		UserIDAliasTTL:            cfg.UserIDAliasTTL,
+		RenameListeners:           cfg.RenameListeners,
+		Moderator:                 cfg.Moderator,
+		Moderation:                memrepo.NewModerationRepo(),
+		Blocks:                    memrepo.NewBlockRepo(),
+		Follows:                   memrepo.NewFollowRepo(),
+		Revisions:                 memrepo.NewRevisionRepo(),
+		Groups:                    memrepo.NewGroupRepo(),
+		Exports:                   memrepo.NewExportRepo(),
+		Archiver:                  export.NewZipArchiver(),
+		ExportTTL:                 cfg.ExportTTL,
+		RejectConfusableNicknames: cfg.RejectConfusableNicknames,
+		UniqueNicknames:           cfg.UniqueNicknames,
+		ReservedNicknames:         cfg.ReservedNicknames,
+	}
+}
+
+func newMailer(dir string) usecase.Mailer {
+	if dir == "" {
+		log.Printf("MAIL_DIR is not set; outgoing mail is discarded")
+		return mailer.Discard{}
+	}
+	return mailer.NewFileMailer(dir)
+}
+
 func (s *Server) routes() {
 	s.mux.HandleFunc("/healthz", s.healthz)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
 	s.mux.HandleFunc("/signup", s.handleSignup)
 	s.mux.HandleFunc("/users/", s.handleUsers) // /users/{user_id}
 	s.mux.HandleFunc("/close", s.handleClose)
+	s.mux.HandleFunc("/sessions", s.handleSessions)
+	s.mux.HandleFunc("/sessions/", s.handleSession) // /sessions/{session_id}
+	s.mux.HandleFunc("/admin/impersonations", s.handleImpersonations)
+	s.mux.HandleFunc("/admin/moderation", s.handleModerationQueue)
+	s.mux.HandleFunc("/admin/moderation/", s.handleModerationDecision) // /admin/moderation/{id}/approve|reject
+	s.mux.HandleFunc("/nicknames/", s.handleNicknames)                 // /nicknames/{nickname}/availability
+	s.mux.HandleFunc("/groups", s.handleGroups)
+	s.mux.HandleFunc("/groups/", s.handleGroup) // /groups/{group_id}[/members/{user_id}]
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -37,7 +220,15 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		log.Printf("%s %s %dms UA=%q", r.Method, r.URL.Path, time.Since(start).Milliseconds(), r.UserAgent())
 	}()
-	s.mux.ServeHTTP(w, r)
+	lw, tr := withLanguage(w, r), r
+	if s.tenantResolution != TenantNone && !tenantExempt(r.URL.Path) {
+		var ok bool
+		if tr, ok = s.withTenant(r); !ok {
+			writeJSON(lw, http.StatusNotFound, messageOnly{Message: "No tenant found"})
+			return
+		}
+	}
+	s.mux.ServeHTTP(lw, tr)
 }
 
 func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
@@ -66,17 +257,17 @@ func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.UC.SignUp(req.UserID, req.Password)
+	user, err := s.uc(r).SignUp(req.UserID, req.Password)
 	if err != nil {
 		switch e := err.(type) {
 		case *usecase.ValidationError:
-			cause := validationCause(e.Reason)
-			writeJSON(w, http.StatusBadRequest, struct {
-				Message string `json:"message"`
-				Cause   string `json:"cause"`
-			}{"Account creation failed", cause})
+			writeJSON(w, http.StatusBadRequest, newValidationFailure("Account creation failed", e))
 			return
 		default:
+			if errors.Is(err, usecase.ErrBusy) {
+				s.writeBusy(w)
+				return
+			}
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
@@ -92,18 +283,22 @@ func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
 
 // /users/{user_id}
 func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
-	if !(r.Method == http.MethodGet || r.Method == http.MethodPatch) {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
 	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/users/"), "/")
-	if len(parts) != 1 || parts[0] == "" {
+	if parts[0] == "" {
 		http.NotFound(w, r)
 		return
 	}
 	pathUserID := parts[0]
+	if len(parts) > 1 {
+		s.handleUserSubresource(w, r, pathUserID, parts[1:])
+		return
+	}
+	if !(r.Method == http.MethodGet || r.Method == http.MethodPatch) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
 
-	authUser, authPass, ok := r.BasicAuth()
+	cred, ok := s.credential(r)
 	if !ok {
 		writeAuthFailed(w)
 		return
@@ -111,32 +306,33 @@ func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		u, err := s.UC.GetUser(pathUserID, authUser, authPass)
+		u, err := s.uc(r).GetUser(pathUserID, cred)
 		if err != nil {
 			if errors.Is(err, usecase.ErrAuthFailed) {
 				writeAuthFailed(w)
 				return
 			}
+			var moved *usecase.MovedError
+			if errors.As(err, &moved) {
+				writeMoved(w, r, moved.UserID)
+				return
+			}
 			if errors.Is(err, usecase.ErrNotFound) {
 				writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
 				return
 			}
+			if errors.Is(err, usecase.ErrBusy) {
+				s.writeBusy(w)
+				return
+			}
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			return
 		}
-		// nickname 未設定なら user_id と同値
-		nn := u.Nickname
-		if nn == "" {
-			nn = u.UserID
-		}
-		var commentPtr *string
-		if u.Comment != "" {
-			c := u.Comment
-			commentPtr = &c
-		}
+		detail := toUserDetail(r, u.User, u.Self)
+		detail.FollowersCount, detail.FollowingCount = &u.Followers, &u.Following
 		writeJSON(w, http.StatusOK, userResponse{
 			Message: "User details by user_id",
-			User:    userDetail{UserID: u.UserID, Nickname: nn, Comment: commentPtr},
+			User:    detail,
 		})
 	case http.MethodPatch:
 		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
@@ -154,66 +350,112 @@ func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
 		// user_id/password が body に含まれるだけで NG
 		forbid := (req.UserID != nil) || (req.Password != nil)
 
-		u, err := s.UC.UpdateUser(pathUserID, authUser, authPass, req.Nickname, req.Comment, forbid)
+		upd := domain.ProfileUpdate{Nickname: req.Nickname, Comment: req.Comment, Attributes: req.Attributes}
+		u, err := s.uc(r).UpdateUser(pathUserID, cred, upd, forbid)
 		if err != nil {
 			if errors.Is(err, usecase.ErrNoPerm) {
 				// 403
-				writeJSON(w, http.StatusForbidden, struct {
-					Message string `json:"message"`
-				}{"No permission for update"})
+				writeForbidden(w, err, "No permission for update")
 				return
 			}
 			if errors.Is(err, usecase.ErrAuthFailed) {
 				writeAuthFailed(w)
 				return
 			}
+			if errors.Is(err, usecase.ErrPendingReview) {
+				// 審査待ち。承認されるまで反映しない
+				writeJSON(w, http.StatusAccepted, messageOnly{Message: "User update is pending review"})
+				return
+			}
 			switch e := err.(type) {
 			case *usecase.ValidationError:
-				cause := validationCause(e.Reason)
 				// usecase が返す理由コードを HTTP 応答用メッセージに変換
-				writeJSON(w, http.StatusBadRequest, struct {
-					Message string `json:"message"`
-					Cause   string `json:"cause"`
-				}{"User updation failed", cause})
+				writeJSON(w, http.StatusBadRequest, newValidationFailure("User updation failed", e))
 				return
 			default:
 				if errors.Is(err, usecase.ErrNotFound) {
 					writeJSON(w, http.StatusNotFound, messageOnly{Message: "No user found"})
 					return
 				}
+				if errors.Is(err, usecase.ErrBusy) {
+					s.writeBusy(w)
+					return
+				}
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 		}
-		// 応答生成（nickname 未設定は user_id）
-		nn := u.Nickname
-		if nn == "" {
-			nn = u.UserID
-		}
-		var commentPtr *string
-		if u.Comment != "" {
-			c := u.Comment
-			commentPtr = &c
-		}
 		writeJSON(w, http.StatusOK, userResponse{
 			Message: "User successfully updated",
-			User:    userDetail{UserID: u.UserID, Nickname: nn, Comment: commentPtr},
+			User:    toUserDetail(r, u, true),
 		})
 	}
 }
 
+// /users/{user_id}/... のサブリソース
+func (s *Server) handleUserSubresource(w http.ResponseWriter, r *http.Request, pathUserID string, sub []string) {
+	switch {
+	case len(sub) == 1 && sub[0] == "email":
+		s.handleUserEmail(w, r, pathUserID)
+	case len(sub) == 2 && sub[0] == "email" && sub[1] == "verify":
+		s.handleUserEmailVerify(w, r, pathUserID)
+	case len(sub) == 1 && sub[0] == "history":
+		s.handleUserHistory(w, r, pathUserID)
+	case len(sub) == 3 && sub[0] == "history" && sub[2] == "revert":
+		s.handleUserHistoryRevert(w, r, pathUserID, sub[1])
+	case len(sub) == 1 && sub[0] == "activity":
+		s.handleUserActivity(w, r, pathUserID)
+	case len(sub) == 1 && (sub[0] == "followers" || sub[0] == "following"):
+		s.handleUserFollows(w, r, pathUserID, sub[0] == "followers")
+	case len(sub) == 2 && sub[0] == "following" && sub[1] != "":
+		s.handleUserFollow(w, r, pathUserID, sub[1])
+	case len(sub) == 1 && sub[0] == "blocks":
+		s.handleUserBlocks(w, r, pathUserID)
+	case len(sub) == 2 && sub[0] == "blocks" && sub[1] != "":
+		s.handleUserBlock(w, r, pathUserID, sub[1])
+	case len(sub) == 1 && (sub[0] == "deactivate" || sub[0] == "reactivate"):
+		s.handleUserActivation(w, r, pathUserID, sub[0] == "deactivate")
+	case len(sub) == 1 && sub[0] == "groups":
+		s.handleUserGroups(w, r, pathUserID)
+	case len(sub) == 1 && sub[0] == "exports":
+		s.handleUserExports(w, r, pathUserID)
+	case len(sub) == 2 && sub[0] == "exports" && sub[1] != "":
+		s.handleUserExport(w, r, pathUserID, sub[1])
+	case len(sub) == 3 && sub[0] == "exports" && sub[2] == "archive":
+		s.handleUserExportArchive(w, r, pathUserID, sub[1])
+	case len(sub) == 1 && sub[0] == "user_id":
+		s.handleUserRename(w, r, pathUserID)
+	case len(sub) == 1 && sub[0] == "privacy":
+		s.handleUserPrivacy(w, r, pathUserID)
+	case len(sub) == 1 && sub[0] == "avatar":
+		s.handleUserAvatar(w, r, pathUserID)
+	case len(sub) == 1 && (sub[0] == "identicon.png" || sub[0] == "identicon.svg"):
+		s.handleUserIdenticon(w, r, pathUserID, sub[0] == "identicon.svg")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
 // POST /close
 func (s *Server) handleClose(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	authUser, authPass, ok := r.BasicAuth()
+	cred, ok := s.credential(r)
 	if !ok {
 		writeAuthFailed(w)
 		return
 	}
-	if err := s.UC.CloseUser(authUser, authPass); err != nil {
+	if err := s.uc(r).CloseUser(cred); err != nil {
+		if errors.Is(err, usecase.ErrBusy) {
+			s.writeBusy(w)
+			return
+		}
+		if errors.Is(err, usecase.ErrImpersonationForbidden) {
+			writeForbidden(w, err, "No permission for close")
+			return
+		}
 		// /close は未存在も 401
 		writeAuthFailed(w)
 		return
@@ -221,6 +463,52 @@ func (s *Server) handleClose(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, messageOnly{Message: "Account and user successfully removed"})
 }
 
+// toUserDetail: 応答用のユーザー表示。メールアドレスは本人（self）にのみ返す
+func toUserDetail(r *http.Request, u *domain.User, self bool) userDetail {
+	// nickname 未設定なら user_id と同値
+	nn := u.Nickname
+	if nn == "" {
+		nn = u.UserID
+	}
+	var commentPtr *string
+	if u.Comment != "" {
+		c := u.Comment
+		commentPtr = &c
+	}
+	d := userDetail{UserID: u.UserID, Nickname: nn, Comment: commentPtr, Attributes: u.Attributes}
+	// アップロードがなければ identicon
+	d.AvatarURL = avatarURL(r, u)
+	if self && u.Email != "" {
+		email, verified := u.Email, u.EmailVerified
+		d.Email = &email
+		d.EmailVerified = &verified
+	}
+	d.CreatedAt = formatTime(u.CreatedAt)
+	d.UpdatedAt = formatTime(u.UpdatedAt)
+	if self {
+		d.LastLoginAt = formatTime(u.LastLoginAt)
+		d.DeactivatedAt = formatTime(u.DeactivatedAt)
+	}
+	return d
+}
+
+// formatTime: RFC 3339（UTC）。ゼロ値は空文字
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// newValidationFailure: cause に加え、失敗した項目ごとのコードと制約の値を errors に含める
+func newValidationFailure(message string, e *usecase.ValidationError) validationFailure {
+	resp := validationFailure{Message: message, Cause: validationCause(e.Reason)}
+	for _, f := range e.Fields {
+		resp.Errors = append(resp.Errors, fieldErrorDetail{Field: f.Field, Code: string(f.Code), Params: f.Params})
+	}
+	return resp
+}
+
 func validationCause(reason usecase.ValidationReason) string {
 	switch reason {
 	case usecase.ValidationReasonCredentialRequired:
@@ -237,6 +525,50 @@ func validationCause(reason usecase.ValidationReason) string {
 		return "Already same user_id is used"
 	case usecase.ValidationReasonNotUpdatableIDOrPass:
 		return "Not updatable user_id and password"
+	case usecase.ValidationReasonEmailInvalid:
+		return "Invalid email address"
+	case usecase.ValidationReasonEmailAlreadyUsed:
+		return "Already same email is used"
+	case usecase.ValidationReasonEmailTokenInvalid:
+		return "Invalid or expired verification token"
+	case usecase.ValidationReasonAvatarTooLarge:
+		return "Image exceeds size limit"
+	case usecase.ValidationReasonAvatarFormat:
+		return "Unsupported image format (PNG, JPEG or GIF)"
+	case usecase.ValidationReasonAvatarDimensions:
+		return "Image dimensions exceed limit"
+	case usecase.ValidationReasonIdenticonSize:
+		return "Size must be between 16 and 1024"
+	case usecase.ValidationReasonAttributeUnknown:
+		return "Unknown profile attribute"
+	case usecase.ValidationReasonAttributeInvalid:
+		return "Profile attribute does not match its schema"
+	case usecase.ValidationReasonAttributeRequired:
+		return "Required profile attribute is missing"
+	case usecase.ValidationReasonNicknameConfusable:
+		return "Nickname is confusable with another user"
+	case usecase.ValidationReasonNicknameTaken:
+		return "Already same nickname is used"
+	case usecase.ValidationReasonNicknameReserved:
+		return "Nickname is reserved"
+	case usecase.ValidationReasonPrivacyInvalid:
+		return "Invalid visibility or field"
+	case usecase.ValidationReasonBlockSelf:
+		return "Cannot block yourself"
+	case usecase.ValidationReasonFollowSelf:
+		return "Cannot follow yourself"
+	case usecase.ValidationReasonCursorInvalid:
+		return "Invalid limit or cursor"
+	case usecase.ValidationReasonContentRejected:
+		return "Containing prohibited words"
+	case usecase.ValidationReasonGroupName:
+		return "Group name must be 1 to 50 characters without control characters"
+	case usecase.ValidationReasonGroupRole:
+		return "Invalid group role"
+	case usecase.ValidationReasonGroupOwnerRequired:
+		return "Group must keep at least one owner"
+	case usecase.ValidationReasonImpersonationReason:
+		return "Required reason (up to 200 characters)"
 	default:
 		return "Validation failed"
 	}
@@ -0,0 +1,17 @@
+package rest
+
+import (
+	"testing"
+
+	"accountapi/internal/infrastructure/mailer"
+)
+
+func TestNewMailerWithoutMailDirDiscards(t *testing.T) {
+	// 配送先がない場合にメールをメモリに溜め続けない
+	if _, ok := newMailer("").(*mailer.Sink); ok {
+		t.Fatal("newMailer(\"\") returned the in-memory test sink")
+	}
+	if _, ok := newMailer(t.TempDir()).(*mailer.FileMailer); !ok {
+		t.Error("newMailer(dir) did not return a FileMailer")
+	}
+}
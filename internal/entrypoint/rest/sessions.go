@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"accountapi/internal/usecase"
+)
+
+// /sessions
+//
+//	POST   : Basic 認証でセッションを発行（トークンはこの応答でのみ返す）
+//	GET    : 有効なセッション一覧
+//	DELETE : すべてのセッションを失効（すべての端末からサインアウト）
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		authUser, authPass, ok := r.BasicAuth()
+		if !ok {
+			writeAuthFailed(w)
+			return
+		}
+		sess, err := s.uc(r).CreateSession(authUser, authPass, s.clientInfo(r))
+		if err != nil {
+			s.writeSessionError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, sessionCreatedResponse{
+			Message: "Session successfully created",
+			Session: toSessionDetail(sess),
+			Token:   sess.Token,
+		})
+	case http.MethodGet:
+		cred, ok := s.credential(r)
+		if !ok {
+			writeAuthFailed(w)
+			return
+		}
+		list, err := s.uc(r).ListSessions(cred)
+		if err != nil {
+			s.writeSessionError(w, err)
+			return
+		}
+		resp := sessionListResponse{Message: "Active sessions", Sessions: make([]sessionDetail, 0, len(list))}
+		for _, sess := range list {
+			resp.Sessions = append(resp.Sessions, toSessionDetail(sess))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodDelete:
+		cred, ok := s.credential(r)
+		if !ok {
+			writeAuthFailed(w)
+			return
+		}
+		if err := s.uc(r).RevokeAllSessions(cred); err != nil {
+			s.writeSessionError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, messageOnly{Message: "All sessions successfully revoked"})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// DELETE /sessions/{session_id}
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/sessions/"), "/")
+	if len(parts) != 1 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	cred, ok := s.credential(r)
+	if !ok {
+		writeAuthFailed(w)
+		return
+	}
+	if err := s.uc(r).RevokeSession(cred, parts[0]); err != nil {
+		s.writeSessionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, messageOnly{Message: "Session successfully revoked"})
+}
+
+func (s *Server) writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrAuthFailed):
+		writeAuthFailed(w)
+	case errors.Is(err, usecase.ErrNoPerm):
+		writeForbidden(w, err, "No permission for access")
+	case errors.Is(err, usecase.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, messageOnly{Message: "No session found"})
+	case errors.Is(err, usecase.ErrBusy):
+		s.writeBusy(w)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func toSessionDetail(sess *usecase.Session) sessionDetail {
+	return sessionDetail{
+		ID:             sess.ID,
+		CreatedAt:      sess.CreatedAt.UTC().Format(time.RFC3339),
+		LastSeenAt:     sess.LastSeenAt.UTC().Format(time.RFC3339),
+		ExpiresAt:      sess.ExpiresAt.UTC().Format(time.RFC3339),
+		IP:             sess.IP,
+		UserAgent:      sess.UserAgent,
+		Current:        sess.Current,
+		ImpersonatedBy: sess.ImpersonatorID,
+	}
+}
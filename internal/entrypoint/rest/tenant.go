@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// TenantResolution: リクエストからテナントを決める方法
+type TenantResolution string
+
+const (
+	// TenantNone: テナントを使わない（すべて domain.DefaultTenant）
+	TenantNone TenantResolution = ""
+	// TenantHost: Host の先頭のラベル（acme.api.example.com → acme）
+	TenantHost TenantResolution = "host"
+	// TenantHeader: X-Tenant-ID ヘッダー
+	TenantHeader TenantResolution = "header"
+	// TenantPath: /tenants/{tenant} で始まるパス。以降のパスは従来どおり
+	TenantPath TenantResolution = "path"
+)
+
+const (
+	tenantHeader     = "X-Tenant-ID"
+	tenantPathPrefix = "/tenants/"
+)
+
+// ParseTenantResolution: TENANT_RESOLUTION の値（空文字・none はテナントを使わない）
+func ParseTenantResolution(s string) (TenantResolution, error) {
+	switch m := TenantResolution(strings.ToLower(s)); m {
+	case TenantHost, TenantHeader, TenantPath:
+		return m, nil
+	case TenantNone, "none":
+		return TenantNone, nil
+	}
+	return TenantNone, fmt.Errorf("invalid tenant resolution %q (host, header or path)", s)
+}
+
+// TenantPolicy: テナントごとの検証・権限の設定。省略した項目は全体の設定（Config）を使う。
+// ただし管理者は全体の設定を引き継がず、Admins・AdminAccounts だけを使う
+type TenantPolicy struct {
+	Admins []string
+	// AdminAccounts: Admins のアカウントの user_id → パスワードハッシュ（bcrypt）
+	AdminAccounts             map[string]string
+	ConcealExistingUsers      *bool
+	UniqueNicknames           *bool
+	RejectConfusableNicknames *bool
+	ReservedNicknames         []string
+	ProfileSchema             *domain.ProfileSchema
+}
+
+// テナント名は DNS のラベルとして使える形式に限る
+var reTenant = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ParseTenantPolicies: テナント名をキーにした JSON オブジェクトを読み込む
+//
+//	{"acme": {"admin_user_ids": ["AcmeAdmin1"], "admin_accounts": {"AcmeAdmin1": "$2a$10$..."}, "nickname_unique": true, "nickname_reject_confusable": false,
+//	          "nickname_reserved": ["support"], "signup_conceal_existing": true, "profile_schema": {"fields": [...]}}}
+func ParseTenantPolicies(data []byte) (map[string]TenantPolicy, error) {
+	var raw map[string]struct {
+		Admins                    []string          `json:"admin_user_ids"`
+		AdminAccounts             map[string]string `json:"admin_accounts"`
+		ConcealExistingUsers      *bool             `json:"signup_conceal_existing"`
+		UniqueNicknames           *bool             `json:"nickname_unique"`
+		RejectConfusableNicknames *bool             `json:"nickname_reject_confusable"`
+		ReservedNicknames         []string          `json:"nickname_reserved"`
+		ProfileSchema             json.RawMessage   `json:"profile_schema"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("no tenants defined")
+	}
+	out := make(map[string]TenantPolicy, len(raw))
+	for name, t := range raw {
+		if !reTenant.MatchString(name) {
+			return nil, fmt.Errorf("invalid tenant name %q", name)
+		}
+		for id, hash := range t.AdminAccounts {
+			if !slices.Contains(t.Admins, id) {
+				return nil, fmt.Errorf("tenant %q: admin account %q is not in admin_user_ids", name, id)
+			}
+			if !domain.ValidPasswordHash(hash) {
+				return nil, fmt.Errorf("tenant %q: invalid password hash for %q", name, id)
+			}
+		}
+		p := TenantPolicy{
+			Admins:                    t.Admins,
+			AdminAccounts:             t.AdminAccounts,
+			ConcealExistingUsers:      t.ConcealExistingUsers,
+			UniqueNicknames:           t.UniqueNicknames,
+			RejectConfusableNicknames: t.RejectConfusableNicknames,
+			ReservedNicknames:         t.ReservedNicknames,
+		}
+		if len(t.ProfileSchema) > 0 && string(t.ProfileSchema) != "null" {
+			schema, err := domain.ParseProfileSchema(t.ProfileSchema)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %q: profile schema: %w", name, err)
+			}
+			p.ProfileSchema = schema
+		}
+		out[name] = p
+	}
+	return out, nil
+}
+
+// apply: 設定のある項目で uc の全体の設定を置き換える。
+// 管理者は常にテナントの設定だけにする（全体の管理者の user_id をテナントで誰かが取得できないように）
+func (p TenantPolicy) apply(uc *usecase.Usecase) {
+	uc.Admins = p.Admins
+	if p.ConcealExistingUsers != nil {
+		uc.ConcealExistingUsers = *p.ConcealExistingUsers
+	}
+	if p.UniqueNicknames != nil {
+		uc.UniqueNicknames = *p.UniqueNicknames
+	}
+	if p.RejectConfusableNicknames != nil {
+		uc.RejectConfusableNicknames = *p.RejectConfusableNicknames
+	}
+	if p.ReservedNicknames != nil {
+		uc.ReservedNicknames = p.ReservedNicknames
+	}
+	if p.ProfileSchema != nil {
+		uc.ProfileSchema = p.ProfileSchema
+	}
+}
+
+type tenantContextKey struct{}
+
+// tenantContext: リクエストのテナント。basePath は TenantPath の場合のパス接頭辞
+type tenantContext struct {
+	uc       *usecase.Usecase
+	basePath string
+}
+
+// withTenant: r のテナントを決め、その Usecase を context に載せる。
+// TenantPath の場合は接頭辞を除いたパスで以降のルーティングを行う。未定義のテナントは false
+func (s *Server) withTenant(r *http.Request) (*http.Request, bool) {
+	var name, rest string
+	switch s.tenantResolution {
+	case TenantHost:
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		name, _, _ = strings.Cut(strings.ToLower(host), ".")
+	case TenantHeader:
+		name = strings.ToLower(strings.TrimSpace(r.Header.Get(tenantHeader)))
+	case TenantPath:
+		var ok bool
+		name, rest, ok = strings.Cut(strings.TrimPrefix(r.URL.Path, tenantPathPrefix), "/")
+		if !ok || !strings.HasPrefix(r.URL.Path, tenantPathPrefix) {
+			return r, false
+		}
+	}
+	uc, ok := s.tenants[name]
+	if !ok {
+		return r, false
+	}
+	tc := &tenantContext{uc: uc}
+	r = r.WithContext(context.WithValue(r.Context(), tenantContextKey{}, tc))
+	if s.tenantResolution == TenantPath {
+		tc.basePath = tenantPathPrefix + name
+		u := *r.URL
+		u.Path, u.RawPath = "/"+rest, ""
+		r.URL = &u
+	}
+	return r, true
+}
+
+// tenantExempt: テナントによらない（監視用の）パス
+func tenantExempt(path string) bool {
+	return path == "/healthz" || path == "/metrics"
+}
+
+// uc: リクエストのテナントの Usecase（テナントを使わない場合は s.UC）
+func (s *Server) uc(r *http.Request) *usecase.Usecase {
+	if tc, ok := r.Context().Value(tenantContextKey{}).(*tenantContext); ok {
+		return tc.uc
+	}
+	return s.UC
+}
+
+// basePath: 応答に含める URL の接頭辞（TenantPath の場合の /tenants/{tenant}）
+func basePath(r *http.Request) string {
+	if tc, ok := r.Context().Value(tenantContextKey{}).(*tenantContext); ok {
+		return tc.basePath
+	}
+	return ""
+}
+
+func userPath(r *http.Request, userID string) string {
+	return basePath(r) + "/users/" + url.PathEscape(userID)
+}
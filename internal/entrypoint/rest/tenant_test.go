@@ -0,0 +1,83 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"accountapi/internal/entrypoint/rest"
+)
+
+func newTenantServer(t *testing.T) *rest.Server {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("PaSSwd4AA"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := rest.New(rest.Config{
+		Admins:           []string{"RootAdmin1"},
+		BlobDir:          t.TempDir(),
+		TenantResolution: rest.TenantHeader,
+		Tenants: map[string]rest.TenantPolicy{
+			"acme": {
+				Admins:        []string{"AcmeAdmin1"},
+				AdminAccounts: map[string]string{"AcmeAdmin1": string(hash)},
+			},
+			"globex": {},
+		},
+	})
+	if err := s.ProvisionAdmins(); err != nil {
+		t.Fatalf("ProvisionAdmins: %v", err)
+	}
+	return s
+}
+
+func tenantRequest(t *testing.T, s *rest.Server, tenant, path, body, userID, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("X-Tenant-ID", tenant)
+	if userID != "" {
+		r.SetBasicAuth(userID, password)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestTenantDoesNotInheritGlobalAdmins(t *testing.T) {
+	s := newTenantServer(t)
+	for _, tenant := range []string{"acme", "globex"} {
+		t.Run(tenant, func(t *testing.T) {
+			w := tenantRequest(t, s, tenant, "/signup", `{"user_id":"TaroYamada","password":"PaSSwd4TY"}`, "", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("signup TaroYamada: %d %s", w.Code, w.Body)
+			}
+			// 全体の管理者の user_id はテナントでは一般の user_id として登録できるが、管理者にはならない
+			w = tenantRequest(t, s, tenant, "/signup", `{"user_id":"RootAdmin1","password":"PaSSwd4RA"}`, "", "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("signup RootAdmin1: %d %s", w.Code, w.Body)
+			}
+			w = tenantRequest(t, s, tenant, "/admin/impersonations", `{"user_id":"TaroYamada","reason":"test"}`, "RootAdmin1", "PaSSwd4RA")
+			if w.Code != http.StatusForbidden {
+				t.Errorf("impersonation by RootAdmin1: %d %s, want 403", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestTenantAdminAccount(t *testing.T) {
+	s := newTenantServer(t)
+	if w := tenantRequest(t, s, "acme", "/signup", `{"user_id":"TaroYamada","password":"PaSSwd4TY"}`, "", ""); w.Code != http.StatusOK {
+		t.Fatalf("signup: %d %s", w.Code, w.Body)
+	}
+	if w := tenantRequest(t, s, "acme", "/signup", `{"user_id":"AcmeAdmin1","password":"PaSSwd4XX"}`, "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("signup AcmeAdmin1: %d %s, want 400", w.Code, w.Body)
+	}
+	w := tenantRequest(t, s, "acme", "/admin/impersonations", `{"user_id":"TaroYamada","reason":"test"}`, "AcmeAdmin1", "PaSSwd4AA")
+	if w.Code != http.StatusOK {
+		t.Errorf("impersonation by AcmeAdmin1: %d %s, want 200", w.Code, w.Body)
+	}
+}
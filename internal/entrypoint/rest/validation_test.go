@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+func TestErrorWritersReturnFieldErrors(t *testing.T) {
+	s := &Server{}
+	vErr := &usecase.ValidationError{
+		Reason: usecase.ValidationReasonProfileConstraint,
+		Fields: []domain.FieldError{{Field: "comment", Code: domain.FieldTooLong, Params: map[string]any{"max": 100}}},
+	}
+	writers := map[string]func(w http.ResponseWriter){
+		"history":      func(w http.ResponseWriter) { s.writeHistoryError(w, vErr, "Profile revert failed", "No user found") },
+		"relationship": func(w http.ResponseWriter) { s.writeRelationshipError(w, vErr, "Block failed") },
+		"privacy":      func(w http.ResponseWriter) { s.writePrivacyError(w, vErr, "Privacy update failed") },
+		"email":        func(w http.ResponseWriter) { s.writeEmailError(w, vErr, "Email update failed") },
+		"group":        func(w http.ResponseWriter) { s.writeGroupError(w, vErr, "Group update failed") },
+		"avatar":       func(w http.ResponseWriter) { s.writeAvatarError(w, vErr) },
+	}
+	for name, write := range writers {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			write(w)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", w.Code)
+			}
+			var body validationFailure
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Cause == "" || len(body.Errors) != 1 || body.Errors[0].Field != "comment" || body.Errors[0].Code != "too_long" {
+				t.Errorf("body = %s, want cause and the comment field error", w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package localfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"accountapi/internal/domain"
+)
+
+// Store keeps blobs as files under Root. Keys are slash-separated relative paths.
+type Store struct {
+	Root string
+}
+
+// New returns a store rooted at dir. The directory is created on first write.
+func New(dir string) *Store {
+	return &Store{Root: dir}
+}
+
+func (s *Store) path(key string) (string, error) {
+	clean := filepath.FromSlash(strings.Trim(key, "/"))
+	if clean == "" || !filepath.IsLocal(clean) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.Root, clean), nil
+}
+
+// Put writes data atomically (temp file + rename).
+func (s *Store) Put(key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *Store) Get(key string) ([]byte, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrNotFound
+	}
+	return data, err
+}
+
+// List returns the names directly under prefix.
+func (s *Store) List(prefix string) ([]string, error) {
+	p, err := s.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		// 書き込み途中の一時ファイルは除く
+		if strings.HasPrefix(e.Name(), ".tmp-") {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
+
+func (s *Store) DeletePrefix(prefix string) error {
+	p, err := s.path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(p)
+}
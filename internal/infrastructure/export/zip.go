@@ -0,0 +1,271 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// ZipArchiver writes export data as a zip of JSON files, one per kind of data,
+// plus the uploaded avatar image when there is one.
+type ZipArchiver struct{}
+
+// NewZipArchiver returns an archiver producing zip files.
+func NewZipArchiver() *ZipArchiver { return &ZipArchiver{} }
+
+func (*ZipArchiver) Archive(data *usecase.ExportData) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"profile.json", toProfile(data)},
+		{"activity.json", toActivity(data.Activity)},
+		{"sessions.json", toSessions(data.Sessions)},
+		{"followers.json", toFollows(data.Followers, func(r *domain.FollowRecord) string { return r.FollowerID })},
+		{"following.json", toFollows(data.Following, func(r *domain.FollowRecord) string { return r.FolloweeID })},
+		{"blocks.json", toBlocks(data.Blocks)},
+		{"groups.json", toGroups(data.Groups)},
+		{"history.json", toRevisions(data.Revisions)},
+		{"pending_changes.json", toPending(data.PendingChanges)},
+	}
+	for _, f := range files {
+		b, err := json.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFile(zw, f.name, data.GeneratedAt, b); err != nil {
+			return nil, err
+		}
+	}
+	if data.Avatar != nil {
+		if err := writeFile(zw, "avatar.png", data.GeneratedAt, data.Avatar); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeFile(zw *zip.Writer, name string, modified time.Time, b []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type profile struct {
+	GeneratedAt   string            `json:"generated_at"`
+	Tenant        string            `json:"tenant,omitempty"`
+	UserID        string            `json:"user_id"`
+	Nickname      string            `json:"nickname"`
+	Comment       string            `json:"comment"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Email         string            `json:"email,omitempty"`
+	EmailVerified bool              `json:"email_verified"`
+	HasAvatar     bool              `json:"has_avatar"`
+	Privacy       privacy           `json:"privacy"`
+	CreatedAt     string            `json:"created_at,omitempty"`
+	UpdatedAt     string            `json:"updated_at,omitempty"`
+	LastLoginAt   string            `json:"last_login_at,omitempty"`
+	DeactivatedAt string            `json:"deactivated_at,omitempty"`
+}
+
+type privacy struct {
+	Profile domain.Visibility            `json:"profile"`
+	Fields  map[string]domain.Visibility `json:"fields,omitempty"`
+}
+
+func toProfile(data *usecase.ExportData) profile {
+	u := data.User
+	return profile{
+		GeneratedAt:   formatTime(data.GeneratedAt),
+		Tenant:        u.Tenant,
+		UserID:        u.UserID,
+		Nickname:      u.Nickname,
+		Comment:       u.Comment,
+		Attributes:    u.Attributes,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+		HasAvatar:     u.AvatarID != "",
+		Privacy:       privacy{Profile: u.Privacy.ProfileVisibility(), Fields: u.Privacy.Fields},
+		CreatedAt:     formatTime(u.CreatedAt),
+		UpdatedAt:     formatTime(u.UpdatedAt),
+		LastLoginAt:   formatTime(u.LastLoginAt),
+		DeactivatedAt: formatTime(u.DeactivatedAt),
+	}
+}
+
+type activity struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Outcome   string `json:"outcome"`
+	At        string `json:"at"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	ActorID   string `json:"actor_id,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	Count     int    `json:"count,omitempty"` // まとめた記録の件数（2 以上のときのみ）
+}
+
+func toActivity(recs []*domain.ActivityRecord) []activity {
+	out := make([]activity, 0, len(recs))
+	for _, r := range recs {
+		a := activity{
+			ID:        strconv.FormatUint(r.ID, 10),
+			Kind:      string(r.Kind),
+			Outcome:   string(r.Outcome),
+			At:        formatTime(r.At),
+			IP:        r.IP,
+			UserAgent: r.UserAgent,
+			ActorID:   r.ActorID,
+			Detail:    r.Detail,
+		}
+		if r.Count > 1 {
+			a.Count = r.Count
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+type session struct {
+	ID             string `json:"id"`
+	CreatedAt      string `json:"created_at"`
+	LastSeenAt     string `json:"last_seen_at"`
+	ExpiresAt      string `json:"expires_at"`
+	IP             string `json:"ip,omitempty"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+}
+
+func toSessions(list []*usecase.Session) []session {
+	out := make([]session, 0, len(list))
+	for _, s := range list {
+		out = append(out, session{
+			ID:             s.ID,
+			CreatedAt:      formatTime(s.CreatedAt),
+			LastSeenAt:     formatTime(s.LastSeenAt),
+			ExpiresAt:      formatTime(s.ExpiresAt),
+			IP:             s.IP,
+			UserAgent:      s.UserAgent,
+			ImpersonatorID: s.ImpersonatorID,
+		})
+	}
+	return out
+}
+
+type follow struct {
+	UserID     string `json:"user_id"`
+	FollowedAt string `json:"followed_at"`
+}
+
+func toFollows(recs []*domain.FollowRecord, other func(*domain.FollowRecord) string) []follow {
+	out := make([]follow, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, follow{UserID: other(r), FollowedAt: formatTime(r.CreatedAt)})
+	}
+	return out
+}
+
+type block struct {
+	UserID    string `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+func toBlocks(recs []*domain.BlockRecord) []block {
+	out := make([]block, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, block{UserID: r.TargetID, CreatedAt: formatTime(r.CreatedAt)})
+	}
+	return out
+}
+
+type group struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+	Pending  bool   `json:"pending"`
+}
+
+func toGroups(list []*usecase.UserGroup) []group {
+	out := make([]group, 0, len(list))
+	for _, g := range list {
+		out = append(out, group{ID: g.ID, Name: g.Name, Role: string(g.Role), JoinedAt: formatTime(g.JoinedAt), Pending: g.Pending})
+	}
+	return out
+}
+
+type snapshot struct {
+	Nickname   string            `json:"nickname"`
+	Comment    string            `json:"comment"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type revision struct {
+	Rev      uint64   `json:"rev"`
+	ActorID  string   `json:"actor_id"`
+	At       string   `json:"at"`
+	Old      snapshot `json:"old"`
+	New      snapshot `json:"new"`
+	RevertOf uint64   `json:"revert_of,omitempty"`
+}
+
+func toSnapshot(s domain.ProfileSnapshot) snapshot {
+	return snapshot{Nickname: s.Nickname, Comment: s.Comment, Attributes: s.Attributes}
+}
+
+func toRevisions(recs []*domain.ProfileRevision) []revision {
+	out := make([]revision, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, revision{
+			Rev:      r.Rev,
+			ActorID:  r.ActorID,
+			At:       formatTime(r.At),
+			Old:      toSnapshot(r.Old),
+			New:      toSnapshot(r.New),
+			RevertOf: r.RevertOf,
+		})
+	}
+	return out
+}
+
+type pendingChange struct {
+	ID         string             `json:"id"`
+	Nickname   *string            `json:"nickname,omitempty"`
+	Comment    *string            `json:"comment,omitempty"`
+	Attributes map[string]*string `json:"attributes,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+func toPending(recs []*domain.ModerationRecord) []pendingChange {
+	out := make([]pendingChange, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, pendingChange{
+			ID:         r.ID,
+			Nickname:   r.Update.Nickname,
+			Comment:    r.Update.Comment,
+			Attributes: r.Update.Attributes,
+			CreatedAt:  formatTime(r.CreatedAt),
+		})
+	}
+	return out
+}
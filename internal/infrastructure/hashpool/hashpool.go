@@ -0,0 +1,139 @@
+package hashpool
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ErrSaturated is returned when no worker became available within the wait timeout
+// or the wait queue is already full.
+var ErrSaturated = errors.New("hash pool saturated")
+
+// WaitBuckets are the upper bounds (seconds) of the queue-wait histogram.
+var WaitBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Config controls the pool limits. Zero values fall back to defaults.
+type Config struct {
+	Concurrency int           // concurrent bcrypt operations (default: NumCPU-1, at least 1)
+	QueueDepth  int           // callers allowed to wait for a worker (default: 4 * Concurrency, negative: no queue)
+	WaitTimeout time.Duration // max time a caller waits in the queue (default: 2s)
+}
+
+// Stats is a snapshot of the pool metrics.
+type Stats struct {
+	InFlight    int
+	Queued      int
+	Completed   uint64
+	Rejected    uint64
+	WaitSum     time.Duration
+	WaitMax     time.Duration
+	WaitBuckets []uint64 // cumulative counts aligned with WaitBuckets
+}
+
+// Pool bounds CPU-heavy work such as password hashing so that bursts cannot starve other requests.
+type Pool struct {
+	cfg   Config
+	slots chan struct{}
+	queue chan struct{}
+
+	mu        sync.Mutex
+	completed uint64
+	rejected  uint64
+	waitSum   time.Duration
+	waitMax   time.Duration
+	buckets   []uint64
+}
+
+// New returns a pool configured with cfg.
+func New(cfg Config) *Pool {
+	if cfg.Concurrency <= 0 {
+		// Leave one core for the rest of the server (health checks, cheap requests).
+		cfg.Concurrency = max(runtime.NumCPU()-1, 1)
+	}
+	if cfg.QueueDepth < 0 {
+		cfg.QueueDepth = 0
+	} else if cfg.QueueDepth == 0 {
+		cfg.QueueDepth = 4 * cfg.Concurrency
+	}
+	if cfg.WaitTimeout <= 0 {
+		cfg.WaitTimeout = 2 * time.Second
+	}
+	return &Pool{
+		cfg:     cfg,
+		slots:   make(chan struct{}, cfg.Concurrency),
+		queue:   make(chan struct{}, cfg.QueueDepth),
+		buckets: make([]uint64, len(WaitBuckets)),
+	}
+}
+
+// Config returns the effective configuration.
+func (p *Pool) Config() Config { return p.cfg }
+
+// Do runs fn on a worker slot, waiting in the queue if all slots are busy.
+// It returns ErrSaturated without running fn when the queue is full or the wait times out.
+func (p *Pool) Do(fn func()) error {
+	start := time.Now()
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		select {
+		case p.queue <- struct{}{}:
+		default:
+			p.reject()
+			return ErrSaturated
+		}
+		timer := time.NewTimer(p.cfg.WaitTimeout)
+		select {
+		case p.slots <- struct{}{}:
+			timer.Stop()
+			<-p.queue
+		case <-timer.C:
+			<-p.queue
+			p.reject()
+			return ErrSaturated
+		}
+	}
+	p.observeWait(time.Since(start))
+	defer func() { <-p.slots }()
+	fn()
+	return nil
+}
+
+func (p *Pool) reject() {
+	p.mu.Lock()
+	p.rejected++
+	p.mu.Unlock()
+}
+
+func (p *Pool) observeWait(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completed++
+	p.waitSum += d
+	if d > p.waitMax {
+		p.waitMax = d
+	}
+	sec := d.Seconds()
+	for i, le := range WaitBuckets {
+		if sec <= le {
+			p.buckets[i]++
+		}
+	}
+}
+
+// Stats returns a snapshot of the current metrics.
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{
+		InFlight:    len(p.slots),
+		Queued:      len(p.queue),
+		Completed:   p.completed,
+		Rejected:    p.rejected,
+		WaitSum:     p.waitSum,
+		WaitMax:     p.waitMax,
+		WaitBuckets: append([]uint64(nil), p.buckets...),
+	}
+}
@@ -0,0 +1,132 @@
+package hashpool
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// hold occupies one worker slot until the returned release func is called.
+func hold(t *testing.T, p *Pool) (release func()) {
+	t.Helper()
+	started, done := make(chan struct{}), make(chan struct{})
+	finished := make(chan error, 1)
+	go func() {
+		finished <- p.Do(func() {
+			close(started)
+			<-done
+		})
+	}()
+	<-started
+	return func() {
+		close(done)
+		if err := <-finished; err != nil {
+			t.Errorf("holding call: %v", err)
+		}
+	}
+}
+
+// waitQueued waits until n callers are queued.
+func waitQueued(t *testing.T, p *Pool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", p.Stats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNewDefaultsLeaveACoreFree(t *testing.T) {
+	cfg := New(Config{}).Config()
+	if want := max(runtime.NumCPU()-1, 1); cfg.Concurrency != want {
+		t.Errorf("Concurrency = %d, want %d", cfg.Concurrency, want)
+	}
+	if cfg.QueueDepth != 4*cfg.Concurrency || cfg.WaitTimeout != 2*time.Second {
+		t.Errorf("defaults = %+v", cfg)
+	}
+	if got := New(Config{QueueDepth: -1}).Config().QueueDepth; got != 0 {
+		t.Errorf("negative QueueDepth = %d, want 0", got)
+	}
+}
+
+func TestDoQueuesUntilASlotIsFree(t *testing.T) {
+	p := New(Config{Concurrency: 1, QueueDepth: 1, WaitTimeout: 5 * time.Second})
+	release := hold(t, p)
+
+	ran := make(chan error, 1)
+	go func() { ran <- p.Do(func() {}) }()
+	waitQueued(t, p, 1)
+	const held = 50 * time.Millisecond
+	time.Sleep(held)
+	release()
+	if err := <-ran; err != nil {
+		t.Fatalf("queued call: %v", err)
+	}
+
+	s := p.Stats()
+	if s.Completed != 2 || s.Rejected != 0 || s.Queued != 0 || s.InFlight != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+	// The wait metric reflects the time spent in the queue.
+	if s.WaitMax < held || s.WaitSum < held {
+		t.Errorf("WaitMax = %v, WaitSum = %v, want at least %v", s.WaitMax, s.WaitSum, held)
+	}
+	for i, le := range WaitBuckets {
+		want := uint64(1) // the holding call did not wait
+		if le >= s.WaitMax.Seconds() {
+			want = 2
+		}
+		if s.WaitBuckets[i] != want {
+			t.Errorf("bucket le=%v: %d, want %d", le, s.WaitBuckets[i], want)
+		}
+	}
+}
+
+func TestDoRejectsAfterWaitTimeout(t *testing.T) {
+	p := New(Config{Concurrency: 1, QueueDepth: 1, WaitTimeout: 20 * time.Millisecond})
+	release := hold(t, p)
+	defer release()
+
+	called := false
+	start := time.Now()
+	err := p.Do(func() { called = true })
+	if !errors.Is(err, ErrSaturated) {
+		t.Fatalf("err = %v, want ErrSaturated", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("rejected after %v, want after the wait timeout", elapsed)
+	}
+	if called {
+		t.Error("fn ran although the call was rejected")
+	}
+	if s := p.Stats(); s.Rejected != 1 || s.Queued != 0 {
+		t.Errorf("stats = %+v, want 1 rejected and an empty queue", s)
+	}
+}
+
+func TestDoRejectsWhenQueueIsFull(t *testing.T) {
+	p := New(Config{Concurrency: 1, QueueDepth: 1, WaitTimeout: 5 * time.Second})
+	release := hold(t, p)
+
+	queued := make(chan error, 1)
+	go func() { queued <- p.Do(func() {}) }()
+	waitQueued(t, p, 1)
+
+	start := time.Now()
+	if err := p.Do(func() { t.Error("fn ran although the queue was full") }); !errors.Is(err, ErrSaturated) {
+		t.Fatalf("err = %v, want ErrSaturated", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("rejected after %v, want immediately", elapsed)
+	}
+	release()
+	if err := <-queued; err != nil {
+		t.Errorf("queued call: %v", err)
+	}
+	if s := p.Stats(); s.Rejected != 1 || s.Completed != 2 {
+		t.Errorf("stats = %+v, want 1 rejected and 2 completed", s)
+	}
+}
@@ -0,0 +1,87 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Message is a delivered mail.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	SentAt  time.Time
+}
+
+// FileMailer writes each message as an .eml file into Dir, for local development.
+type FileMailer struct {
+	Dir string
+	seq atomic.Uint64
+}
+
+// NewFileMailer returns a mailer that stores messages under dir.
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{Dir: dir}
+}
+
+// Send writes the message to Dir, creating the directory if needed.
+func (m *FileMailer) Send(to, subject, body string) error {
+	if err := os.MkdirAll(m.Dir, 0o700); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%06d.eml", now.Format("20060102T150405.000000000"), m.seq.Add(1))
+	content := fmt.Sprintf("Date: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		now.Format(time.RFC1123Z), to, mime.QEncoding.Encode("utf-8", subject), body)
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o600)
+}
+
+// Discard drops every message without delivering it, logging only the recipient and subject
+// (the body carries verification tokens). Used when no delivery is configured.
+type Discard struct{}
+
+func (Discard) Send(to, subject, body string) error {
+	log.Printf("mail to %s not delivered (no mail delivery configured): %s", to, subject)
+	return nil
+}
+
+// Sink keeps messages in memory instead of delivering them (fake SMTP sink for tests).
+type Sink struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewSink returns an empty sink.
+func NewSink() *Sink { return &Sink{} }
+
+func (s *Sink) Send(to, subject, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, Message{To: to, Subject: subject, Body: body, SentAt: time.Now()})
+	return nil
+}
+
+// Messages returns a copy of every message received so far.
+func (s *Sink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Last returns the most recent message sent to the address.
+func (s *Sink) Last(to string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].To == to {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -0,0 +1,177 @@
+package moderation
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+
+	"accountapi/internal/domain"
+)
+
+// Rule is a prohibited word and the action taken when it appears.
+type Rule struct {
+	Word   string
+	Action domain.ModerationAction
+}
+
+// WordList flags text containing any of its words. Matching is substring-based so that it
+// works for languages without word boundaries such as Japanese, and ignores case,
+// full-width/half-width forms and the hiragana/katakana distinction.
+type WordList struct {
+	rules []foldedRule
+}
+
+type foldedRule struct {
+	word   []rune // folded
+	orig   string
+	action domain.ModerationAction
+}
+
+// NewWordList builds a filter from rules. Empty words are ignored.
+func NewWordList(rules []Rule) *WordList {
+	wl := &WordList{}
+	for _, r := range rules {
+		folded := fold(r.Word)
+		if len(folded) == 0 {
+			continue
+		}
+		wl.rules = append(wl.rules, foldedRule{word: folded, orig: r.Word, action: r.Action})
+	}
+	return wl
+}
+
+// ParseWordList reads one word per line. A line may start with "reject:", "mask:" or "hold:"
+// to override defaultAction; blank lines and lines starting with "#" are skipped.
+func ParseWordList(data []byte, defaultAction domain.ModerationAction) (*WordList, error) {
+	if !validAction(defaultAction) {
+		return nil, fmt.Errorf("unknown moderation action %q", defaultAction)
+	}
+	var rules []Rule
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rule := Rule{Word: text, Action: defaultAction}
+		if prefix, word, ok := strings.Cut(text, ":"); ok && validAction(domain.ModerationAction(prefix)) {
+			rule = Rule{Word: strings.TrimSpace(word), Action: domain.ModerationAction(prefix)}
+		}
+		if rule.Word == "" {
+			return nil, fmt.Errorf("line %d: empty word", line)
+		}
+		rules = append(rules, rule)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return NewWordList(rules), nil
+}
+
+func validAction(a domain.ModerationAction) bool {
+	return a == domain.ModerationReject || a == domain.ModerationMask || a == domain.ModerationHold
+}
+
+// Moderate checks text against the list. With ModerationMask, matched characters are replaced by '*'.
+func (wl *WordList) Moderate(field, text string) domain.ModerationVerdict {
+	v := domain.ModerationVerdict{Action: domain.ModerationAllow, Text: text}
+	orig := []rune(text)
+	folded := foldRunes(orig)
+	masked := make([]bool, len(orig))
+	for _, r := range wl.rules {
+		found := false
+		for i := 0; i+len(r.word) <= len(folded); i++ {
+			if !equalFolded(folded[i:i+len(r.word)], r.word) {
+				continue
+			}
+			found = true
+			for j := folded[i].start; j < folded[i+len(r.word)-1].end; j++ {
+				masked[j] = true
+			}
+		}
+		if !found {
+			continue
+		}
+		v.Matches = append(v.Matches, r.orig)
+		if r.action.Severity() > v.Action.Severity() {
+			v.Action = r.action
+		}
+	}
+	if v.Action == domain.ModerationMask {
+		for i := range orig {
+			if masked[i] {
+				orig[i] = '*'
+			}
+		}
+		v.Text = string(orig)
+	}
+	return v
+}
+
+// foldedRune is a folded character and the range [start, end) of original runes it came from.
+type foldedRune struct {
+	r          rune
+	start, end int
+}
+
+func fold(s string) []rune {
+	f := foldRunes([]rune(s))
+	out := make([]rune, len(f))
+	for i, c := range f {
+		out[i] = c.r
+	}
+	return out
+}
+
+// foldRunes applies NFKC, drops format characters (zero-width spaces, bidi controls and so on),
+// composes combining and half-width voiced sound marks with the preceding character, and maps
+// katakana to hiragana and letters to lower case. Each folded character remembers which original
+// runes it covers so that matches can be masked in the original text.
+func foldRunes(rs []rune) []foldedRune {
+	out := make([]foldedRune, 0, len(rs))
+	for i, r := range rs {
+		if unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		// 単独の濁点・半濁点（゛゜）は NFKC で空白＋結合文字になるため、先に結合文字に寄せる
+		switch r {
+		case '゛':
+			r = '\u3099'
+		case '゜':
+			r = '\u309A'
+		}
+		for _, c := range norm.NFKC.String(string(r)) {
+			if f := []rune(width.Fold.String(string(c))); len(f) == 1 {
+				c = f[0]
+			}
+			if unicode.Is(unicode.Mn, c) && len(out) > 0 {
+				prev := &out[len(out)-1]
+				if composed := []rune(norm.NFC.String(string([]rune{prev.r, c}))); len(composed) == 1 {
+					prev.r = composed[0]
+					prev.end = i + 1
+					continue
+				}
+			}
+			// カタカナ → ひらがな
+			if c >= 'ァ' && c <= 'ヶ' {
+				c -= 'ァ' - 'ぁ'
+			}
+			out = append(out, foldedRune{r: unicode.ToLower(c), start: i, end: i + 1})
+		}
+	}
+	return out
+}
+
+func equalFolded(a []foldedRune, b []rune) bool {
+	for i := range a {
+		if a[i].r != b[i] {
+			return false
+		}
+	}
+	return true
+}
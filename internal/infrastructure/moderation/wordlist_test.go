@@ -0,0 +1,51 @@
+package moderation
+
+import (
+	"testing"
+
+	"accountapi/internal/domain"
+)
+
+func TestWordListMatchesNormalizedForms(t *testing.T) {
+	wl := NewWordList([]Rule{{Word: "ばか", Action: domain.ModerationMask}, {Word: "spam", Action: domain.ModerationMask}})
+	tests := []struct {
+		name string
+		in   string
+		want string // マスク後
+	}{
+		{"composed", "ばかです", "**です"},
+		{"decomposed (NFD)", "\u306f\u3099\u304bです", "***です"},
+		{"katakana", "バカです", "**です"},
+		{"half-width katakana", "\uff8a\uff9e\uff76です", "***です"},
+		{"spacing voiced mark", "は\u309bか", "***"},
+		{"zero width space", "ば\u200bか", "***"},
+		{"bidi control", "ば\u202eか", "***"},
+		{"full-width latin", "ＳＰＡＭ!", "****!"},
+		{"no match", "ばんか", "ばんか"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := wl.Moderate("comment", tt.in)
+			if v.Text != tt.want {
+				t.Errorf("Moderate(%q).Text = %q, want %q", tt.in, v.Text, tt.want)
+			}
+			wantAction := domain.ModerationMask
+			if tt.in == tt.want {
+				wantAction = domain.ModerationAllow
+			}
+			if v.Action != wantAction {
+				t.Errorf("Moderate(%q).Action = %q, want %q", tt.in, v.Action, wantAction)
+			}
+		})
+	}
+}
+
+func TestWordListFoldsListedWords(t *testing.T) {
+	// 一覧の語も同じ規則で畳み込む
+	wl := NewWordList([]Rule{{Word: "\uff8a\uff9e\uff76", Action: domain.ModerationReject}})
+	for _, in := range []string{"ばか", "バカ", "\u306f\u3099\u304b", "ば\u200bか"} {
+		if v := wl.Moderate("nickname", in); v.Action != domain.ModerationReject {
+			t.Errorf("Moderate(%q).Action = %q, want reject", in, v.Action)
+		}
+	}
+}
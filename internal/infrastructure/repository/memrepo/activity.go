@@ -0,0 +1,125 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// ActivityRepo stores per-user activity history in process memory with retention limits.
+type ActivityRepo struct {
+	mu         sync.Mutex
+	seq        uint64
+	events     map[string][]*domain.ActivityRecord // user ID -> oldest first
+	maxPerUser int
+	maxAge     time.Duration
+}
+
+// NewActivityRepo returns an activity store keeping at most maxPerUser events per user
+// and dropping events older than maxAge. Zero disables the corresponding limit.
+func NewActivityRepo(maxPerUser int, maxAge time.Duration) *ActivityRepo {
+	return &ActivityRepo{
+		events:     make(map[string][]*domain.ActivityRecord),
+		maxPerUser: maxPerUser,
+		maxAge:     maxAge,
+	}
+}
+
+func (r *ActivityRepo) Append(rec *domain.ActivityRecord, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.append(rec, now)
+	return nil
+}
+
+func (r *ActivityRepo) AppendCoalesced(rec *domain.ActivityRecord, window time.Duration, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 保持期間を過ぎたものにはまとめない
+	list := r.prune(r.events[rec.UserID], now)
+	if n := len(list); n > 0 {
+		last := list[n-1]
+		if last.Kind == rec.Kind && last.Outcome == rec.Outcome && last.ActorID == rec.ActorID && rec.At.Sub(last.At) < window {
+			last.Count++
+			last.At, last.IP, last.UserAgent = rec.At, rec.IP, rec.UserAgent
+			rec.ID, rec.Count = last.ID, last.Count
+			return nil
+		}
+	}
+	r.append(rec, now)
+	return nil
+}
+
+// append adds rec as a new event. Caller must hold r.mu.
+func (r *ActivityRepo) append(rec *domain.ActivityRecord, now time.Time) {
+	r.seq++
+	c := *rec
+	c.ID = r.seq
+	c.Count = 1
+	rec.ID, rec.Count = c.ID, c.Count
+	list := append(r.events[rec.UserID], &c)
+	list = r.prune(list, now)
+	if r.maxPerUser > 0 && len(list) > r.maxPerUser {
+		list = append([]*domain.ActivityRecord(nil), list[len(list)-r.maxPerUser:]...)
+	}
+	r.events[rec.UserID] = list
+}
+
+func (r *ActivityRepo) List(userID string, beforeID uint64, limit int, now time.Time) ([]*domain.ActivityRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.prune(r.events[userID], now)
+	if len(list) == 0 {
+		delete(r.events, userID)
+		return nil, nil
+	}
+	r.events[userID] = list
+	var out []*domain.ActivityRecord
+	for i := len(list) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		if beforeID != 0 && list[i].ID >= beforeID {
+			continue
+		}
+		c := *list[i]
+		out = append(out, &c)
+	}
+	return out, nil
+}
+
+func (r *ActivityRepo) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.events, userID)
+	return nil
+}
+
+// prune drops events past maxAge. Caller must hold r.mu.
+func (r *ActivityRepo) prune(list []*domain.ActivityRecord, now time.Time) []*domain.ActivityRecord {
+	if r.maxAge <= 0 {
+		return list
+	}
+	cutoff := now.Add(-r.maxAge)
+	i := 0
+	for i < len(list) && list[i].At.Before(cutoff) {
+		i++
+	}
+	if i == 0 {
+		return list
+	}
+	return append([]*domain.ActivityRecord(nil), list[i:]...)
+}
+
+func (r *ActivityRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list, ok := r.events[oldID]
+	if !ok {
+		return nil
+	}
+	for _, rec := range list {
+		rec.UserID = newID
+	}
+	r.events[newID] = append(list, r.events[newID]...)
+	delete(r.events, oldID)
+	return nil
+}
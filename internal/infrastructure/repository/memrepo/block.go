@@ -0,0 +1,125 @@
+package memrepo
+
+import (
+	"sort"
+	"sync"
+
+	"accountapi/internal/domain"
+)
+
+// BlockRepo stores user blocks in process memory.
+type BlockRepo struct {
+	mu      sync.RWMutex
+	byOwner map[string]map[string]*domain.BlockRecord // owner ID -> target ID -> record
+	// blockedBy lets DeleteByUser find the owners that blocked a user.
+	blockedBy map[string]map[string]struct{} // target ID -> owner IDs
+}
+
+// NewBlockRepo returns an initialized in-memory block store.
+func NewBlockRepo() *BlockRepo {
+	return &BlockRepo{
+		byOwner:   make(map[string]map[string]*domain.BlockRecord),
+		blockedBy: make(map[string]map[string]struct{}),
+	}
+}
+
+func (r *BlockRepo) Add(rec *domain.BlockRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	targets, ok := r.byOwner[rec.OwnerID]
+	if !ok {
+		targets = make(map[string]*domain.BlockRecord)
+		r.byOwner[rec.OwnerID] = targets
+	}
+	if _, exists := targets[rec.TargetID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	c := *rec
+	targets[rec.TargetID] = &c
+	owners, ok := r.blockedBy[rec.TargetID]
+	if !ok {
+		owners = make(map[string]struct{})
+		r.blockedBy[rec.TargetID] = owners
+	}
+	owners[rec.OwnerID] = struct{}{}
+	return nil
+}
+
+func (r *BlockRepo) Remove(ownerID, targetID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byOwner[ownerID][targetID]; !ok {
+		return domain.ErrNotFound
+	}
+	r.remove(ownerID, targetID)
+	return nil
+}
+
+// remove deletes a block known to exist. Caller must hold r.mu.
+func (r *BlockRepo) remove(ownerID, targetID string) {
+	delete(r.byOwner[ownerID], targetID)
+	if len(r.byOwner[ownerID]) == 0 {
+		delete(r.byOwner, ownerID)
+	}
+	delete(r.blockedBy[targetID], ownerID)
+	if len(r.blockedBy[targetID]) == 0 {
+		delete(r.blockedBy, targetID)
+	}
+}
+
+func (r *BlockRepo) Blocked(ownerID, targetID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.byOwner[ownerID][targetID]
+	return ok, nil
+}
+
+// ListByOwner returns the owner's blocks ordered by creation time.
+func (r *BlockRepo) ListByOwner(ownerID string) ([]*domain.BlockRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*domain.BlockRecord, 0, len(r.byOwner[ownerID]))
+	for _, rec := range r.byOwner[ownerID] {
+		c := *rec
+		out = append(out, &c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *BlockRepo) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for targetID := range r.byOwner[userID] {
+		r.remove(userID, targetID)
+	}
+	for ownerID := range r.blockedBy[userID] {
+		r.remove(ownerID, userID)
+	}
+	return nil
+}
+
+func (r *BlockRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if targets, ok := r.byOwner[oldID]; ok {
+		for targetID, rec := range targets {
+			rec.OwnerID = newID
+			delete(r.blockedBy[targetID], oldID)
+			r.blockedBy[targetID][newID] = struct{}{}
+		}
+		r.byOwner[newID] = targets
+		delete(r.byOwner, oldID)
+	}
+	if owners, ok := r.blockedBy[oldID]; ok {
+		for ownerID := range owners {
+			rec := r.byOwner[ownerID][oldID]
+			rec.TargetID = newID
+			delete(r.byOwner[ownerID], oldID)
+			r.byOwner[ownerID][newID] = rec
+		}
+		r.blockedBy[newID] = owners
+		delete(r.blockedBy, oldID)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package memrepo
+
+import (
+	"sort"
+	"sync"
+
+	"accountapi/internal/domain"
+)
+
+// ExportRepo stores personal data export requests in process memory.
+type ExportRepo struct {
+	mu      sync.RWMutex
+	exports map[string]*domain.ExportRecord // key: export ID
+}
+
+// NewExportRepo returns an initialized in-memory export store.
+func NewExportRepo() *ExportRepo {
+	return &ExportRepo{exports: make(map[string]*domain.ExportRecord)}
+}
+
+func (r *ExportRepo) Create(rec *domain.ExportRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.exports[rec.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	c := *rec
+	r.exports[rec.ID] = &c
+	return nil
+}
+
+func (r *ExportRepo) Update(rec *domain.ExportRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.exports[rec.ID]; !ok {
+		return domain.ErrNotFound
+	}
+	c := *rec
+	r.exports[rec.ID] = &c
+	return nil
+}
+
+func (r *ExportRepo) Find(userID, id string) (*domain.ExportRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.exports[id]
+	if !ok || rec.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	c := *rec
+	return &c, nil
+}
+
+func (r *ExportRepo) FindByID(id string) (*domain.ExportRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.exports[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *rec
+	return &c, nil
+}
+
+// ListByUser returns the user's exports ordered by creation time.
+func (r *ExportRepo) ListByUser(userID string) ([]*domain.ExportRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []*domain.ExportRecord
+	for _, rec := range r.exports {
+		if rec.UserID == userID {
+			c := *rec
+			out = append(out, &c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *ExportRepo) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.exports[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.exports, id)
+	return nil
+}
+
+func (r *ExportRepo) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, rec := range r.exports {
+		if rec.UserID == userID {
+			delete(r.exports, id)
+		}
+	}
+	return nil
+}
+
+func (r *ExportRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rec := range r.exports {
+		if rec.UserID == oldID {
+			rec.UserID = newID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package memrepo
+
+import (
+	"sort"
+	"sync"
+
+	"accountapi/internal/domain"
+)
+
+// FollowRepo stores the follow graph in process memory, indexed in both directions.
+type FollowRepo struct {
+	mu        sync.RWMutex
+	seq       uint64
+	following map[string]map[string]*domain.FollowRecord // follower ID -> followee ID -> record
+	followers map[string]map[string]*domain.FollowRecord // followee ID -> follower ID -> record
+}
+
+// NewFollowRepo returns an initialized in-memory follow store.
+func NewFollowRepo() *FollowRepo {
+	return &FollowRepo{
+		following: make(map[string]map[string]*domain.FollowRecord),
+		followers: make(map[string]map[string]*domain.FollowRecord),
+	}
+}
+
+func (r *FollowRepo) Add(rec *domain.FollowRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.following[rec.FollowerID][rec.FolloweeID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	r.seq++
+	c := *rec
+	c.ID = r.seq
+	rec.ID = c.ID
+	link(r.following, rec.FollowerID, rec.FolloweeID, &c)
+	link(r.followers, rec.FolloweeID, rec.FollowerID, &c)
+	return nil
+}
+
+func link(ix map[string]map[string]*domain.FollowRecord, from, to string, rec *domain.FollowRecord) {
+	m, ok := ix[from]
+	if !ok {
+		m = make(map[string]*domain.FollowRecord)
+		ix[from] = m
+	}
+	m[to] = rec
+}
+
+func unlink(ix map[string]map[string]*domain.FollowRecord, from, to string) {
+	delete(ix[from], to)
+	if len(ix[from]) == 0 {
+		delete(ix, from)
+	}
+}
+
+func (r *FollowRepo) Remove(followerID, followeeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.following[followerID][followeeID]; !ok {
+		return domain.ErrNotFound
+	}
+	unlink(r.following, followerID, followeeID)
+	unlink(r.followers, followeeID, followerID)
+	return nil
+}
+
+func (r *FollowRepo) ListFollowers(userID string, beforeID uint64, limit int) ([]*domain.FollowRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return page(r.followers[userID], beforeID, limit), nil
+}
+
+func (r *FollowRepo) ListFollowing(userID string, beforeID uint64, limit int) ([]*domain.FollowRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return page(r.following[userID], beforeID, limit), nil
+}
+
+// page returns records newest first. Caller must hold r.mu.
+func page(m map[string]*domain.FollowRecord, beforeID uint64, limit int) []*domain.FollowRecord {
+	out := make([]*domain.FollowRecord, 0, len(m))
+	for _, rec := range m {
+		if beforeID != 0 && rec.ID >= beforeID {
+			continue
+		}
+		c := *rec
+		out = append(out, &c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+func (r *FollowRepo) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for followeeID := range r.following[userID] {
+		unlink(r.followers, followeeID, userID)
+	}
+	for followerID := range r.followers[userID] {
+		unlink(r.following, followerID, userID)
+	}
+	delete(r.following, userID)
+	delete(r.followers, userID)
+	return nil
+}
+
+func (r *FollowRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 両方向の索引は同じレコードを指しているため、レコードの書き換えは一度でよい
+	if m, ok := r.following[oldID]; ok {
+		for followeeID, rec := range m {
+			rec.FollowerID = newID
+			delete(r.followers[followeeID], oldID)
+			r.followers[followeeID][newID] = rec
+		}
+		r.following[newID] = m
+		delete(r.following, oldID)
+	}
+	if m, ok := r.followers[oldID]; ok {
+		for followerID, rec := range m {
+			rec.FolloweeID = newID
+			delete(r.following[followerID], oldID)
+			r.following[followerID][newID] = rec
+		}
+		r.followers[newID] = m
+		delete(r.followers, oldID)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package memrepo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// GroupRepo stores groups and their memberships in process memory.
+type GroupRepo struct {
+	mu      sync.RWMutex
+	groups  map[string]*domain.GroupRecord
+	members map[string]map[string]*membership // group ID -> user ID -> membership
+	// byUser lets ListByUser and RenameUser find a user's groups.
+	byUser map[string]map[string]struct{} // user ID -> group IDs
+	seq    uint64
+}
+
+// membership keeps the order in which members joined, since JoinedAt may tie.
+type membership struct {
+	domain.GroupMembership
+	seq uint64
+}
+
+// NewGroupRepo returns an initialized in-memory group store.
+func NewGroupRepo() *GroupRepo {
+	return &GroupRepo{
+		groups:  make(map[string]*domain.GroupRecord),
+		members: make(map[string]map[string]*membership),
+		byUser:  make(map[string]map[string]struct{}),
+	}
+}
+
+func (r *GroupRepo) Create(g *domain.GroupRecord, owner *domain.GroupMembership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.groups[g.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	c := *g
+	r.groups[g.ID] = &c
+	r.members[g.ID] = make(map[string]*membership)
+	r.add(owner)
+	return nil
+}
+
+func (r *GroupRepo) Find(id string) (*domain.GroupRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.groups[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *g
+	return &c, nil
+}
+
+func (r *GroupRepo) Rename(id, name string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g, ok := r.groups[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	g.Name = name
+	g.UpdatedAt = at
+	return nil
+}
+
+func (r *GroupRepo) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.groups[id]; !ok {
+		return domain.ErrNotFound
+	}
+	for userID := range r.members[id] {
+		r.remove(id, userID)
+	}
+	delete(r.members, id)
+	delete(r.groups, id)
+	return nil
+}
+
+func (r *GroupRepo) AddMember(m *domain.GroupMembership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	members, ok := r.members[m.GroupID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if _, exists := members[m.UserID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	r.add(m)
+	return nil
+}
+
+// add stores a membership of an existing group. Caller must hold r.mu.
+func (r *GroupRepo) add(m *domain.GroupMembership) {
+	r.seq++
+	r.members[m.GroupID][m.UserID] = &membership{GroupMembership: *m, seq: r.seq}
+	groups, ok := r.byUser[m.UserID]
+	if !ok {
+		groups = make(map[string]struct{})
+		r.byUser[m.UserID] = groups
+	}
+	groups[m.GroupID] = struct{}{}
+}
+
+func (r *GroupRepo) Accept(groupID, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.members[groupID][userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	m.Pending = false
+	m.JoinedAt = at
+	return nil
+}
+
+func (r *GroupRepo) SetRole(groupID, userID string, role domain.GroupRole) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.members[groupID][userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	m.Role = role
+	return nil
+}
+
+func (r *GroupRepo) RemoveMember(groupID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.members[groupID][userID]; !ok {
+		return domain.ErrNotFound
+	}
+	r.remove(groupID, userID)
+	return nil
+}
+
+// remove deletes a membership known to exist. Caller must hold r.mu.
+func (r *GroupRepo) remove(groupID, userID string) {
+	delete(r.members[groupID], userID)
+	delete(r.byUser[userID], groupID)
+	if len(r.byUser[userID]) == 0 {
+		delete(r.byUser, userID)
+	}
+}
+
+func (r *GroupRepo) Membership(groupID, userID string) (*domain.GroupMembership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.members[groupID][userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := m.GroupMembership
+	return &c, nil
+}
+
+// ListMembers returns the group's members in the order they joined.
+func (r *GroupRepo) ListMembers(groupID string) ([]*domain.GroupMembership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]*membership, 0, len(r.members[groupID]))
+	for _, m := range r.members[groupID] {
+		list = append(list, m)
+	}
+	return sortMemberships(list), nil
+}
+
+// ListByUser returns the user's memberships in the order they joined.
+func (r *GroupRepo) ListByUser(userID string) ([]*domain.GroupMembership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]*membership, 0, len(r.byUser[userID]))
+	for groupID := range r.byUser[userID] {
+		list = append(list, r.members[groupID][userID])
+	}
+	return sortMemberships(list), nil
+}
+
+func sortMemberships(list []*membership) []*domain.GroupMembership {
+	sort.Slice(list, func(i, j int) bool { return list[i].seq < list[j].seq })
+	out := make([]*domain.GroupMembership, len(list))
+	for i, m := range list {
+		c := m.GroupMembership
+		out[i] = &c
+	}
+	return out
+}
+
+func (r *GroupRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	groups, ok := r.byUser[oldID]
+	if !ok {
+		return nil
+	}
+	for groupID := range groups {
+		m := r.members[groupID][oldID]
+		m.UserID = newID
+		delete(r.members[groupID], oldID)
+		r.members[groupID][newID] = m
+	}
+	r.byUser[newID] = groups
+	delete(r.byUser, oldID)
+	return nil
+}
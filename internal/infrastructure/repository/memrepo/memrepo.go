@@ -2,19 +2,65 @@ package memrepo
 
 import (
 	"sync"
+	"time"
 
 	"accountapi/internal/domain"
 )
 
 // MemoryRepo stores user records in process memory for testing or lightweight usage.
+// Each tenant has its own namespace; user IDs, emails and nicknames are only unique within a tenant.
 type MemoryRepo struct {
-	mu    sync.RWMutex
-	users map[string]*domain.UserRecord
+	mu      sync.RWMutex
+	tenants map[string]*tenantStore
+}
+
+// tenantStore holds the records and indexes of a single tenant.
+type tenantStore struct {
+	users  map[string]*domain.UserRecord
+	emails map[string]string // verified email key -> user ID
+	// secondary indexes keyed by domain.NicknameSkeleton
+	nicknames nameIndex        // nickname -> user IDs
+	userIDs   nameIndex        // user ID -> user IDs
+	aliases   map[string]alias // former user ID -> current
+}
+
+// alias is a former user ID kept after Rename.
+type alias struct {
+	userID    string
+	expiresAt time.Time
 }
 
 // New returns an initialized in-memory repository.
 func New() *MemoryRepo {
-	return &MemoryRepo{users: make(map[string]*domain.UserRecord)}
+	return &MemoryRepo{tenants: make(map[string]*tenantStore)}
+}
+
+// emptyStore stands in for tenants without records. Its maps are nil, so callers
+// must only write to a namespace after finding the record in it.
+var emptyStore = &tenantStore{}
+
+// store returns the tenant's namespace, creating it for the first record. Caller must hold r.mu for writing.
+func (r *MemoryRepo) store(tenant string) *tenantStore {
+	t, ok := r.tenants[tenant]
+	if !ok {
+		t = &tenantStore{
+			users:     make(map[string]*domain.UserRecord),
+			emails:    make(map[string]string),
+			nicknames: make(nameIndex),
+			userIDs:   make(nameIndex),
+			aliases:   make(map[string]alias),
+		}
+		r.tenants[tenant] = t
+	}
+	return t
+}
+
+// peek returns the tenant's namespace, or emptyStore if it has no records yet. Caller must hold r.mu.
+func (r *MemoryRepo) peek(tenant string) *tenantStore {
+	if t, ok := r.tenants[tenant]; ok {
+		return t
+	}
+	return emptyStore
 }
 
 func clone(rec *domain.UserRecord) *domain.UserRecord {
@@ -22,47 +68,307 @@ func clone(rec *domain.UserRecord) *domain.UserRecord {
 		return nil
 	}
 	c := *rec
+	c.Attributes = cloneAttributes(rec.Attributes)
+	c.Privacy = clonePrivacy(rec.Privacy)
 	return &c
 }
 
+func clonePrivacy(p domain.PrivacySettings) domain.PrivacySettings {
+	c := domain.PrivacySettings{Profile: p.Profile}
+	if p.Fields != nil {
+		c.Fields = make(map[string]domain.Visibility, len(p.Fields))
+		for k, v := range p.Fields {
+			c.Fields[k] = v
+		}
+	}
+	return c
+}
+
+func cloneAttributes(attrs map[string]string) map[string]string {
+	if attrs == nil {
+		return nil
+	}
+	c := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		c[k] = v
+	}
+	return c
+}
+
 func (r *MemoryRepo) Create(rec *domain.UserRecord) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, exists := r.users[rec.UserID]; exists {
+	t := r.store(rec.Tenant)
+	if _, exists := t.users[rec.UserID]; exists {
 		return domain.ErrAlreadyExists
 	}
-	r.users[rec.UserID] = clone(rec)
+	if a, ok := t.aliases[rec.UserID]; ok {
+		if rec.CreatedAt.Before(a.expiresAt) {
+			return domain.ErrAlreadyExists
+		}
+		delete(t.aliases, rec.UserID)
+	}
+	c := clone(rec)
+	t.users[rec.UserID] = c
+	t.userIDs.add(c.UserID, c.UserID)
+	t.nicknames.add(c.Nickname, c.UserID)
 	return nil
 }
 
-func (r *MemoryRepo) FindByID(userID string) (*domain.UserRecord, error) {
+func (r *MemoryRepo) FindByID(tenant, userID string) (*domain.UserRecord, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	rec, ok := r.users[userID]
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
 	if !ok {
 		return nil, domain.ErrNotFound
 	}
 	return clone(rec), nil
 }
 
-func (r *MemoryRepo) UpdateProfile(userID, nickname, comment string) error {
+func (r *MemoryRepo) UpdateProfile(tenant, userID string, p domain.Profile, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if p.Nickname != rec.Nickname {
+		if err := t.checkNickname(userID, p.Nickname, p.NicknamePolicy); err != nil {
+			return err
+		}
+	}
+	t.nicknames.remove(rec.Nickname, userID)
+	rec.Nickname = p.Nickname
+	t.nicknames.add(rec.Nickname, userID)
+	rec.Comment = p.Comment
+	rec.Attributes = cloneAttributes(p.Attributes)
+	rec.UpdatedAt = at
+	return nil
+}
+
+func (r *MemoryRepo) CheckNickname(tenant, userID, nickname string, policy domain.NicknamePolicy) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t := r.peek(tenant)
+	return t.checkNickname(userID, nickname, policy)
+}
+
+// checkNickname applies the nickname policy against other users of the tenant. Caller must hold r.mu.
+func (t *tenantStore) checkNickname(userID, nickname string, policy domain.NicknamePolicy) error {
+	if nickname == "" {
+		return nil
+	}
+	key := domain.NicknameSkeleton(nickname)
+	if (policy.Unique || policy.RejectConfusable) && t.nicknames.takenByOther(key, userID) {
+		// 一意性を求める設定なら重複として扱う
+		if policy.Unique {
+			return domain.ErrNicknameTaken
+		}
+		return domain.ErrNicknameConfusable
+	}
+	if policy.RejectConfusable && t.userIDs.takenByOther(key, userID) {
+		return domain.ErrNicknameConfusable
+	}
+	return nil
+}
+
+func (r *MemoryRepo) CheckEmail(tenant, userID, email string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t := r.peek(tenant)
+	if _, ok := t.users[userID]; !ok {
+		return domain.ErrNotFound
+	}
+	if owner, taken := t.emails[domain.EmailKey(email)]; taken && owner != userID {
+		return domain.ErrAlreadyExists
+	}
+	return nil
+}
+
+func (r *MemoryRepo) UpdateEmail(tenant, userID, email, tokenHash string, tokenExpiresAt, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if email != "" {
+		if owner, taken := t.emails[domain.EmailKey(email)]; taken && owner != userID {
+			return domain.ErrAlreadyExists
+		}
+	}
+	t.releaseEmail(rec)
+	rec.Email = email
+	rec.EmailVerified = false
+	rec.EmailTokenHash = tokenHash
+	rec.EmailTokenExpiresAt = tokenExpiresAt
+	if tokenHash != "" {
+		rec.EmailSentAt = at
+	}
+	rec.UpdatedAt = at
+	return nil
+}
+
+func (r *MemoryRepo) MarkEmailVerified(tenant, userID, email string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
+	if !ok || rec.Email == "" || rec.Email != email {
+		return domain.ErrNotFound
+	}
+	key := domain.EmailKey(email)
+	if owner, taken := t.emails[key]; taken && owner != userID {
+		return domain.ErrAlreadyExists
+	}
+	t.emails[key] = userID
+	rec.EmailVerified = true
+	rec.EmailTokenHash = ""
+	rec.EmailTokenExpiresAt = time.Time{}
+	rec.UpdatedAt = at
+	return nil
+}
+
+func (r *MemoryRepo) UpdateAvatar(tenant, userID, avatarID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	rec.AvatarID = avatarID
+	rec.UpdatedAt = at
+	return nil
+}
+
+func (r *MemoryRepo) UpdatePrivacy(tenant, userID string, p domain.PrivacySettings, at time.Time) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	rec, ok := r.users[userID]
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
 	if !ok {
 		return domain.ErrNotFound
 	}
-	rec.Nickname = nickname
-	rec.Comment = comment
+	rec.Privacy = clonePrivacy(p)
+	rec.UpdatedAt = at
 	return nil
 }
 
-func (r *MemoryRepo) Delete(userID string) error {
+func (r *MemoryRepo) UpdateDeactivation(tenant, userID string, deactivated bool, at time.Time) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, ok := r.users[userID]; !ok {
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
+	if !ok {
 		return domain.ErrNotFound
 	}
-	delete(r.users, userID)
+	rec.DeactivatedAt = time.Time{}
+	if deactivated {
+		rec.DeactivatedAt = at
+	}
+	rec.UpdatedAt = at
 	return nil
 }
+
+func (r *MemoryRepo) RecordLogin(tenant, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	rec.LastLoginAt = at
+	return nil
+}
+
+// releaseEmail drops the record's verified email from the uniqueness index. Caller must hold r.mu.
+func (t *tenantStore) releaseEmail(rec *domain.UserRecord) {
+	if rec.Email == "" || !rec.EmailVerified {
+		return
+	}
+	key := domain.EmailKey(rec.Email)
+	if t.emails[key] == rec.UserID {
+		delete(t.emails, key)
+	}
+}
+
+func (r *MemoryRepo) Delete(tenant, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.peek(tenant)
+	rec, ok := t.users[userID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	t.releaseEmail(rec)
+	t.nicknames.remove(rec.Nickname, userID)
+	t.userIDs.remove(rec.UserID, userID)
+	delete(t.users, userID)
+	// 削除したユーザーの旧 user_id は再登録できるようにする
+	for id, a := range t.aliases {
+		if a.userID == userID {
+			delete(t.aliases, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepo) Rename(tenant, oldID, newID string, aliasUntil, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.peek(tenant)
+	rec, ok := t.users[oldID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if _, exists := t.users[newID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	if a, ok := t.aliases[newID]; ok {
+		// 自身の旧 user_id には期限内でも戻せる
+		if a.userID != oldID && at.Before(a.expiresAt) {
+			return domain.ErrAlreadyExists
+		}
+		delete(t.aliases, newID)
+	}
+	// 以前の user_id の別名も新しい user_id を指すようにする（期限切れのものはここで消す）
+	for id, a := range t.aliases {
+		if !at.Before(a.expiresAt) {
+			delete(t.aliases, id)
+			continue
+		}
+		if a.userID == oldID {
+			a.userID = newID
+			t.aliases[id] = a
+		}
+	}
+	t.aliases[oldID] = alias{userID: newID, expiresAt: aliasUntil}
+	if rec.Email != "" && rec.EmailVerified {
+		t.emails[domain.EmailKey(rec.Email)] = newID
+	}
+	t.nicknames.remove(rec.Nickname, oldID)
+	t.nicknames.add(rec.Nickname, newID)
+	t.userIDs.remove(oldID, oldID)
+	t.userIDs.add(newID, newID)
+	rec.UserID = newID
+	rec.UpdatedAt = at
+	delete(t.users, oldID)
+	t.users[newID] = rec
+	return nil
+}
+
+func (r *MemoryRepo) ResolveAlias(tenant, userID string, at time.Time) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t := r.peek(tenant)
+	a, ok := t.aliases[userID]
+	if !ok || !at.Before(a.expiresAt) {
+		return "", domain.ErrNotFound
+	}
+	return a.userID, nil
+}
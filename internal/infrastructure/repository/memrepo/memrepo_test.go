@@ -0,0 +1,101 @@
+package memrepo_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/infrastructure/repository/memrepo"
+)
+
+var now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newRepoWith(t *testing.T, users map[string]string) *memrepo.MemoryRepo {
+	t.Helper()
+	r := memrepo.New()
+	for userID, nickname := range users {
+		if err := r.Create(&domain.UserRecord{UserID: userID, Nickname: nickname, CreatedAt: now}); err != nil {
+			t.Fatalf("Create(%s): %v", userID, err)
+		}
+	}
+	return r
+}
+
+func TestCheckNickname(t *testing.T) {
+	r := newRepoWith(t, map[string]string{"TaroYamada": "Taro", "HanakoSato": "はなこ"})
+	unique := domain.NicknamePolicy{Unique: true}
+	confusable := domain.NicknamePolicy{RejectConfusable: true}
+	both := domain.NicknamePolicy{Unique: true, RejectConfusable: true}
+
+	tests := []struct {
+		name     string
+		userID   string
+		nickname string
+		policy   domain.NicknamePolicy
+		want     error
+	}{
+		{"same nickname", "HanakoSato", "Taro", unique, domain.ErrNicknameTaken},
+		{"case only", "HanakoSato", "tARO", unique, domain.ErrNicknameTaken},
+		{"cyrillic lookalike, unique", "HanakoSato", "Таrо", unique, domain.ErrNicknameTaken},
+		{"cyrillic lookalike, confusable", "HanakoSato", "Таrо", confusable, domain.ErrNicknameConfusable},
+		{"digit lookalike", "HanakoSato", "Tar0", both, domain.ErrNicknameTaken},
+		{"another user's user_id", "HanakoSato", "taroyamada", confusable, domain.ErrNicknameConfusable},
+		{"user_id is not checked without RejectConfusable", "HanakoSato", "taroyamada", unique, nil},
+		{"no policy", "HanakoSato", "Taro", domain.NicknamePolicy{}, nil},
+		{"own nickname", "TaroYamada", "taro", both, nil},
+		{"own user_id", "TaroYamada", "TaroYamada", both, nil},
+		{"different nickname", "HanakoSato", "Jiro", both, nil},
+		{"empty", "HanakoSato", "", both, nil},
+	}
+	for _, tt := range tests {
+		if err := r.CheckNickname("", tt.userID, tt.nickname, tt.policy); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestUpdateProfileMovesNicknameIndex(t *testing.T) {
+	r := newRepoWith(t, map[string]string{"TaroYamada": "Taro", "HanakoSato": "はなこ"})
+	policy := domain.NicknamePolicy{Unique: true}
+
+	if err := r.UpdateProfile("", "HanakoSato", domain.Profile{Nickname: "taro", NicknamePolicy: policy}, now); !errors.Is(err, domain.ErrNicknameTaken) {
+		t.Fatalf("taken nickname: err = %v, want ErrNicknameTaken", err)
+	}
+	if err := r.UpdateProfile("", "TaroYamada", domain.Profile{Nickname: "Jiro", NicknamePolicy: policy}, now); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	// 手放した nickname は他のユーザーが使える
+	if err := r.UpdateProfile("", "HanakoSato", domain.Profile{Nickname: "taro", NicknamePolicy: policy}, now); err != nil {
+		t.Errorf("released nickname: err = %v", err)
+	}
+	if err := r.CheckNickname("", "HanakoSato", "JIRO", policy); !errors.Is(err, domain.ErrNicknameTaken) {
+		t.Errorf("new nickname: err = %v, want ErrNicknameTaken", err)
+	}
+}
+
+func TestRenameMovesNicknameIndex(t *testing.T) {
+	r := newRepoWith(t, map[string]string{"TaroYamada": "Taro", "HanakoSato": "はなこ"})
+	policy := domain.NicknamePolicy{Unique: true, RejectConfusable: true}
+	if err := r.Rename("", "TaroYamada", "TaroSuzuki", now.Add(time.Hour), now); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	// nickname は新しい user_id のものとして索引される
+	if err := r.CheckNickname("", "TaroSuzuki", "Taro", policy); err != nil {
+		t.Errorf("own nickname after rename: err = %v", err)
+	}
+	if err := r.CheckNickname("", "HanakoSato", "Taro", policy); !errors.Is(err, domain.ErrNicknameTaken) {
+		t.Errorf("other user after rename: err = %v, want ErrNicknameTaken", err)
+	}
+	// user_id の索引も移る
+	if err := r.CheckNickname("", "HanakoSato", "TaroYamada", policy); err != nil {
+		t.Errorf("old user_id: err = %v, want no conflict", err)
+	}
+	if err := r.CheckNickname("", "HanakoSato", "TaroSuzuki", policy); !errors.Is(err, domain.ErrNicknameConfusable) {
+		t.Errorf("new user_id: err = %v, want ErrNicknameConfusable", err)
+	}
+	if err := r.CheckNickname("", "TaroSuzuki", "TaroSuzuki", policy); err != nil {
+		t.Errorf("own new user_id: err = %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package memrepo
+
+import (
+	"sort"
+	"sync"
+
+	"accountapi/internal/domain"
+)
+
+// ModerationRepo stores profile changes held for review in process memory.
+type ModerationRepo struct {
+	mu      sync.RWMutex
+	records map[string]*domain.ModerationRecord // key: record ID
+	byUser  map[string]string                   // user ID -> record ID
+}
+
+// NewModerationRepo returns an initialized in-memory review queue.
+func NewModerationRepo() *ModerationRepo {
+	return &ModerationRepo{
+		records: make(map[string]*domain.ModerationRecord),
+		byUser:  make(map[string]string),
+	}
+}
+
+func cloneModeration(rec *domain.ModerationRecord) *domain.ModerationRecord {
+	c := *rec
+	c.Update = cloneProfileUpdate(rec.Update)
+	c.Matches = append([]string(nil), rec.Matches...)
+	return &c
+}
+
+func cloneProfileUpdate(upd domain.ProfileUpdate) domain.ProfileUpdate {
+	c := domain.ProfileUpdate{Nickname: cloneString(upd.Nickname), Comment: cloneString(upd.Comment)}
+	if upd.Attributes != nil {
+		c.Attributes = make(map[string]*string, len(upd.Attributes))
+		for k, v := range upd.Attributes {
+			c.Attributes[k] = cloneString(v)
+		}
+	}
+	return c
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+func (r *ModerationRepo) Put(rec *domain.ModerationRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.records[rec.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	if prev, ok := r.byUser[rec.UserID]; ok {
+		delete(r.records, prev)
+	}
+	r.records[rec.ID] = cloneModeration(rec)
+	r.byUser[rec.UserID] = rec.ID
+	return nil
+}
+
+// List returns pending records, oldest first.
+func (r *ModerationRepo) List(limit int) ([]*domain.ModerationRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*domain.ModerationRecord, 0, len(r.records))
+	for _, rec := range r.records {
+		out = append(out, cloneModeration(rec))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *ModerationRepo) Find(id string) (*domain.ModerationRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.records[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneModeration(rec), nil
+}
+
+func (r *ModerationRepo) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.records[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.byUser, rec.UserID)
+	delete(r.records, id)
+	return nil
+}
+
+func (r *ModerationRepo) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.byUser[userID]; ok {
+		delete(r.records, id)
+		delete(r.byUser, userID)
+	}
+	return nil
+}
+
+func (r *ModerationRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.byUser[oldID]
+	if !ok {
+		return nil
+	}
+	r.records[id].UserID = newID
+	r.byUser[newID] = id
+	delete(r.byUser, oldID)
+	return nil
+}
@@ -0,0 +1,41 @@
+package memrepo
+
+import "accountapi/internal/domain"
+
+// nameIndex maps a display name skeleton to the IDs of users using it.
+// Names are compared with domain.NicknameSkeleton, so case and confusable variants share an entry.
+type nameIndex map[string]map[string]struct{}
+
+func (ix nameIndex) add(name, userID string) {
+	if name == "" {
+		return
+	}
+	key := domain.NicknameSkeleton(name)
+	owners, ok := ix[key]
+	if !ok {
+		owners = make(map[string]struct{})
+		ix[key] = owners
+	}
+	owners[userID] = struct{}{}
+}
+
+func (ix nameIndex) remove(name, userID string) {
+	if name == "" {
+		return
+	}
+	key := domain.NicknameSkeleton(name)
+	delete(ix[key], userID)
+	if len(ix[key]) == 0 {
+		delete(ix, key)
+	}
+}
+
+// takenByOther reports whether a user other than userID owns the skeleton.
+func (ix nameIndex) takenByOther(key, userID string) bool {
+	for owner := range ix[key] {
+		if owner != userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package memrepo
+
+import (
+	"sync"
+
+	"accountapi/internal/domain"
+)
+
+// RevisionRepo stores profile revisions in process memory.
+type RevisionRepo struct {
+	mu   sync.RWMutex
+	revs map[string][]*domain.ProfileRevision // user ID -> oldest first (index = Rev-1)
+}
+
+// NewRevisionRepo returns an initialized in-memory revision store.
+func NewRevisionRepo() *RevisionRepo {
+	return &RevisionRepo{revs: make(map[string][]*domain.ProfileRevision)}
+}
+
+func cloneRevision(rec *domain.ProfileRevision) *domain.ProfileRevision {
+	c := *rec
+	c.Old.Attributes = cloneAttributes(rec.Old.Attributes)
+	c.New.Attributes = cloneAttributes(rec.New.Attributes)
+	return &c
+}
+
+func (r *RevisionRepo) Append(rec *domain.ProfileRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.revs[rec.UserID]
+	rec.Rev = uint64(len(list)) + 1
+	r.revs[rec.UserID] = append(list, cloneRevision(rec))
+	return nil
+}
+
+func (r *RevisionRepo) List(userID string, beforeRev uint64, limit int) ([]*domain.ProfileRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := r.revs[userID]
+	end := len(list)
+	if beforeRev != 0 && beforeRev-1 < uint64(end) {
+		end = int(beforeRev - 1)
+	}
+	var out []*domain.ProfileRevision
+	for i := end - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		out = append(out, cloneRevision(list[i]))
+	}
+	return out, nil
+}
+
+func (r *RevisionRepo) Find(userID string, rev uint64) (*domain.ProfileRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := r.revs[userID]
+	if rev == 0 || rev > uint64(len(list)) {
+		return nil, domain.ErrNotFound
+	}
+	return cloneRevision(list[rev-1]), nil
+}
+
+func (r *RevisionRepo) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.revs, userID)
+	return nil
+}
+
+func (r *RevisionRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list, ok := r.revs[oldID]
+	if !ok {
+		return nil
+	}
+	for _, rec := range list {
+		rec.UserID = newID
+		if rec.ActorID == oldID {
+			rec.ActorID = newID
+		}
+	}
+	r.revs[newID] = list
+	delete(r.revs, oldID)
+	return nil
+}
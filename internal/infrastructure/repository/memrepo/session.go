@@ -0,0 +1,131 @@
+package memrepo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// SessionRepo stores login sessions in process memory.
+type SessionRepo struct {
+	mu       sync.RWMutex
+	sessions map[string]*domain.SessionRecord // key: session ID
+	byToken  map[string]string                // token hash -> session ID
+}
+
+// NewSessionRepo returns an initialized in-memory session repository.
+func NewSessionRepo() *SessionRepo {
+	return &SessionRepo{
+		sessions: make(map[string]*domain.SessionRecord),
+		byToken:  make(map[string]string),
+	}
+}
+
+func cloneSession(rec *domain.SessionRecord) *domain.SessionRecord {
+	if rec == nil {
+		return nil
+	}
+	c := *rec
+	return &c
+}
+
+func (r *SessionRepo) Create(rec *domain.SessionRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.sessions[rec.ID]; exists {
+		return domain.ErrAlreadyExists
+	}
+	if _, exists := r.byToken[rec.TokenHash]; exists {
+		return domain.ErrAlreadyExists
+	}
+	r.sessions[rec.ID] = cloneSession(rec)
+	r.byToken[rec.TokenHash] = rec.ID
+	return nil
+}
+
+func (r *SessionRepo) FindByTokenHash(tokenHash string) (*domain.SessionRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.byToken[tokenHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneSession(r.sessions[id]), nil
+}
+
+// ListByUser returns the user's sessions ordered by creation time.
+func (r *SessionRepo) ListByUser(userID string) ([]*domain.SessionRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []*domain.SessionRecord
+	for _, rec := range r.sessions {
+		if rec.UserID == userID {
+			out = append(out, cloneSession(rec))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *SessionRepo) Touch(id string, at time.Time, ip, userAgent string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.sessions[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	rec.LastSeenAt = at
+	rec.IP = ip
+	rec.UserAgent = userAgent
+	return nil
+}
+
+func (r *SessionRepo) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.sessions[id]
+	if !ok || rec.UserID != userID {
+		return domain.ErrNotFound
+	}
+	delete(r.byToken, rec.TokenHash)
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *SessionRepo) DeleteByUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, rec := range r.sessions {
+		if rec.UserID == userID {
+			delete(r.byToken, rec.TokenHash)
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+// DeleteExpired removes the user's sessions that expired at or before at.
+func (r *SessionRepo) DeleteExpired(userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, rec := range r.sessions {
+		if rec.UserID == userID && !at.Before(rec.ExpiresAt) {
+			delete(r.byToken, rec.TokenHash)
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (r *SessionRepo) RenameUser(oldID, newID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rec := range r.sessions {
+		if rec.UserID == oldID {
+			rec.UserID = newID
+		}
+	}
+	return nil
+}
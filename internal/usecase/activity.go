@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// ListActivity の 1 ページの件数（既定・上限）
+const (
+	DefaultActivityPageSize = 20
+	MaxActivityPageSize     = 100
+)
+
+// Activity: 利用履歴の 1 件
+type Activity struct {
+	ID        uint64
+	Kind      domain.ActivityKind
+	Outcome   domain.ActivityOutcome
+	At        time.Time
+	IP        string
+	UserAgent string
+	ActorID   string
+	Detail    string
+	Count     int // 続けて失敗した認証をまとめた件数（At・IP・UserAgent は最後のもの）
+}
+
+// ListActivity: 本人のみ。新しい順に最大 limit 件を返し、続きがあれば次のカーソル（最後の ID）を返す
+func (u *Usecase) ListActivity(pathUserID string, cred Credential, beforeID uint64, limit int) ([]*Activity, uint64, error) {
+	if _, err := u.authenticateOwner(pathUserID, cred); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = DefaultActivityPageSize
+	}
+	if limit > MaxActivityPageSize {
+		limit = MaxActivityPageSize
+	}
+	// 続きの有無を判定するため 1 件多く取得する
+	recs, err := u.Activity.List(pathUserID, beforeID, limit+1, u.now())
+	if err != nil {
+		return nil, 0, err
+	}
+	var next uint64
+	if len(recs) > limit {
+		recs = recs[:limit]
+		next = recs[limit-1].ID
+	}
+	out := make([]*Activity, 0, len(recs))
+	for _, rec := range recs {
+		out = append(out, &Activity{
+			ID:        rec.ID,
+			Kind:      rec.Kind,
+			Outcome:   rec.Outcome,
+			At:        rec.At,
+			IP:        rec.IP,
+			UserAgent: rec.UserAgent,
+			ActorID:   rec.ActorID,
+			Detail:    rec.Detail,
+			Count:     rec.Count,
+		})
+	}
+	return out, next, nil
+}
+
+// failedLoginCoalesceWindow: 続けて失敗した認証を 1 件の利用履歴にまとめる間隔
+const failedLoginCoalesceWindow = time.Hour
+
+// recordLogin: パスワードによる認証を利用履歴に記録し、成功なら最終ログイン日時を更新する。
+// 失敗は他人でも繰り返せるため、続けて失敗した分は 1 件にまとめて件数を数える（保持上限で他の記録が押し出されないように）
+func (u *Usecase) recordLogin(d *domain.User, ok bool, client ClientInfo) {
+	if !ok {
+		rec := &domain.ActivityRecord{
+			UserID: d.UserID, Kind: domain.ActivityLogin, Outcome: domain.ActivityFailure,
+			At: u.now(), IP: client.IP, UserAgent: client.UserAgent,
+		}
+		_ = u.Activity.AppendCoalesced(rec, failedLoginCoalesceWindow, rec.At)
+		return
+	}
+	u.record(d.UserID, domain.ActivityLogin, domain.ActivitySuccess, client)
+	// 利用履歴と同じく、記録の失敗で認証を失敗させない
+	d.LastLoginAt = u.now()
+	_ = u.Repo.RecordLogin(u.Tenant, d.UserID, d.LastLoginAt)
+}
+
+// record: 利用履歴に追記する。記録の失敗で本来の操作を失敗させない
+func (u *Usecase) record(userID string, kind domain.ActivityKind, outcome domain.ActivityOutcome, client ClientInfo) {
+	u.appendActivity(&domain.ActivityRecord{UserID: userID, Kind: kind, Outcome: outcome}, client)
+}
+
+// recordFor: 認証済み利用者の操作として記録する。なりすまし中は操作した管理者も記録する
+func (u *Usecase) recordFor(p *principal, kind domain.ActivityKind, outcome domain.ActivityOutcome, client ClientInfo) {
+	u.appendActivity(&domain.ActivityRecord{UserID: p.user.UserID, Kind: kind, Outcome: outcome, ActorID: p.actorID}, client)
+}
+
+func (u *Usecase) appendActivity(rec *domain.ActivityRecord, client ClientInfo) {
+	rec.At = u.now()
+	rec.IP = client.IP
+	rec.UserAgent = client.UserAgent
+	_ = u.Activity.Append(rec, rec.At)
+}
@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/infrastructure/repository/memrepo"
+)
+
+func TestActivityRetentionFollowsClock(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	uc.Activity = memrepo.NewActivityRepo(0, time.Hour)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+
+	list, _, err := uc.ListActivity("TaroYamada", cred, 0, 0)
+	if err != nil {
+		t.Fatalf("ListActivity: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("events = %d, want 1 login", len(list))
+	}
+	first := list[0].ID
+
+	clock.Advance(2 * time.Hour)
+	list, _, err = uc.ListActivity("TaroYamada", cred, 0, 0)
+	if err != nil {
+		t.Fatalf("ListActivity: %v", err)
+	}
+	if len(list) != 1 || list[0].ID == first {
+		t.Errorf("events = %d, want only the login after the retention period", len(list))
+	}
+}
+
+// 続けて失敗した認証は 1 件にまとめ、保持件数の上限で他の記録を押し出さない
+func TestFailedLoginsAreCoalesced(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	uc.Activity = memrepo.NewActivityRepo(5, 0)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+	comment := "hello"
+	if _, err := uc.UpdateUser("TaroYamada", cred, domain.ProfileUpdate{Comment: &comment}, false); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+
+	const attempts = 12
+	for i := range attempts {
+		clock.Advance(time.Minute)
+		wrong := basic("TaroYamada", "WrongPass1")
+		wrong.Client.IP = "192.0.2." + strconv.Itoa(i+1)
+		if _, err := uc.GetUser("TaroYamada", wrong); err == nil {
+			t.Fatal("GetUser with a wrong password succeeded")
+		}
+	}
+	list, _, err := uc.ListActivity("TaroYamada", cred, 0, 0)
+	if err != nil {
+		t.Fatalf("ListActivity: %v", err)
+	}
+	// 新しい順: 認証成功（ListActivity）・まとめた失敗・プロフィール変更・認証成功（UpdateUser）
+	if len(list) != 4 {
+		t.Fatalf("events = %d, want 4", len(list))
+	}
+	failed := list[1]
+	if failed.Kind != domain.ActivityLogin || failed.Outcome != domain.ActivityFailure || failed.Count != attempts {
+		t.Errorf("failed logins = %+v, want one record counting %d attempts", failed, attempts)
+	}
+	if failed.IP != "192.0.2.12" || !failed.At.Equal(clock.Now()) {
+		t.Errorf("failed logins: ip = %q, at = %v, want the last attempt", failed.IP, failed.At)
+	}
+	if list[2].Kind != domain.ActivityProfileUpdate {
+		t.Errorf("events[2] = %s, want profile_update to be kept", list[2].Kind)
+	}
+
+	// 成功を挟んだ後や間隔が空いた後の失敗は別の記録になる
+	clock.Advance(2 * time.Hour)
+	if _, err := uc.GetUser("TaroYamada", basic("TaroYamada", "WrongPass1")); err == nil {
+		t.Fatal("GetUser with a wrong password succeeded")
+	}
+	list, _, err = uc.ListActivity("TaroYamada", cred, 0, 2)
+	if err != nil {
+		t.Fatalf("ListActivity: %v", err)
+	}
+	if list[1].Outcome != domain.ActivityFailure || list[1].Count != 1 {
+		t.Errorf("new failure = %+v, want a separate record", list[1])
+	}
+}
@@ -0,0 +1,70 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+func TestSignUpRejectsAdminUserID(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.Admins = []string{"RootAdmin1"}
+
+	if _, err := uc.SignUp("RootAdmin1", "PaSSwd4RA"); !isValidation(err, usecase.ValidationReasonUserAlreadyExists) {
+		t.Fatalf("err = %v, want user_already_exists", err)
+	}
+	if _, err := uc.Repo.FindByID(domain.DefaultTenant, "RootAdmin1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("admin account created by signup: err = %v", err)
+	}
+
+	// 秘匿モードでは既存の user_id と同じく成功の応答だが、アカウントは作られない
+	uc.ConcealExistingUsers = true
+	if _, err := uc.SignUp("RootAdmin1", "PaSSwd4RA"); err != nil {
+		t.Fatalf("with conceal: err = %v", err)
+	}
+	if _, err := uc.Repo.FindByID(domain.DefaultTenant, "RootAdmin1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("admin account created by concealed signup: err = %v", err)
+	}
+}
+
+func TestRenameUserRejectsAdminUserID(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.Admins = []string{"RootAdmin1"}
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	_, err := uc.RenameUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "RootAdmin1")
+	if !isValidation(err, usecase.ValidationReasonUserAlreadyExists) {
+		t.Fatalf("err = %v, want user_already_exists", err)
+	}
+	if _, err := uc.Impersonate(basic("TaroYamada", "PaSSwd4TY"), "RootAdmin1", "test"); !errors.Is(err, usecase.ErrNoPerm) {
+		t.Errorf("Impersonate: err = %v, want ErrNoPerm", err)
+	}
+}
+
+func TestProvisionAdmin(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.Admins = []string{"RootAdmin1"}
+	hash, err := bcrypt.GenerateFromPassword([]byte("PaSSwd4RA"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.ProvisionAdmin("TaroYamada", string(hash)); err == nil {
+		t.Error("ProvisionAdmin for a non-admin: want error")
+	}
+	if err := uc.ProvisionAdmin("RootAdmin1", "plain-password"); err == nil {
+		t.Error("ProvisionAdmin with a non-bcrypt hash: want error")
+	}
+	for range 2 {
+		if err := uc.ProvisionAdmin("RootAdmin1", string(hash)); err != nil {
+			t.Fatalf("ProvisionAdmin: %v", err)
+		}
+	}
+	if _, err := uc.GetUser("RootAdmin1", basic("RootAdmin1", "PaSSwd4RA")); err != nil {
+		t.Errorf("admin cannot authenticate: %v", err)
+	}
+}
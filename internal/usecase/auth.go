@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	"accountapi/internal/domain"
+)
+
+// Credential: リクエストの認証情報。Token（Bearer）があればセッションで、
+// ClientCert ならクライアント証明書で検証済みの UserID として、どちらでもなければ Basic（UserID/Password）で認証する
+type Credential struct {
+	UserID   string
+	Password string
+	Token    string
+	// ClientCert: UserID は TLS 層で検証済みのクライアント証明書から割り当てたもの（パスワード不要）
+	ClientCert bool
+	Client     ClientInfo
+}
+
+// ClientInfo: リクエスト元の情報（セッションに記録する）
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// principal: 認証済みの利用者
+type principal struct {
+	user      *domain.User
+	sessionID string // セッション認証の場合のみ
+	actorID   string // 管理者がなりすましている場合、その管理者の user_id
+}
+
+func (p *principal) impersonating() bool { return p.actorID != "" }
+
+// actor: 操作した user_id（なりすまし中は管理者）
+func (p *principal) actor() string {
+	if p.impersonating() {
+		return p.actorID
+	}
+	return p.user.UserID
+}
+
+func (u *Usecase) authenticate(cred Credential) (*principal, error) {
+	switch {
+	case cred.Token != "":
+		return u.authenticateSession(cred)
+	case cred.ClientCert:
+		return u.authenticateCertificate(cred)
+	}
+	d, err := u.authenticatePassword(cred.UserID, cred.Password, cred.Client)
+	if err != nil {
+		return nil, err
+	}
+	return &principal{user: d}, nil
+}
+
+// authenticateOwner: 本人のみ許可する操作の認証。
+// Basic の場合は user_id の一致を先に確認し（403）、未存在は 404 とする（PATCH /users/{id} の仕様）。
+// 未存在の場合もダミーのハッシュ比較を行い、応答時間からは存在を区別できないようにする
+func (u *Usecase) authenticateOwner(pathUserID string, cred Credential) (*principal, error) {
+	if cred.Token != "" || cred.ClientCert {
+		p, err := u.authenticate(cred)
+		if err != nil {
+			return nil, err
+		}
+		if p.user.UserID != pathUserID {
+			return nil, ErrNoPerm
+		}
+		return p, nil
+	}
+	if pathUserID != cred.UserID {
+		return nil, ErrNoPerm // 403
+	}
+	rec, err := u.Repo.FindByID(u.Tenant, cred.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			if err := u.hash(func() { domain.VerifyDummyPassword(cred.Password) }); err != nil {
+				return nil, err
+			}
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	d := toDomain(rec)
+	ok, err := u.verifyPassword(d, cred.Password)
+	if err != nil {
+		return nil, err
+	}
+	u.recordLogin(d, ok, cred.Client)
+	if !ok {
+		return nil, ErrAuthFailed
+	}
+	return &principal{user: d}, nil
+}
+
+// authenticateActiveOwner: authenticateOwner に加え、利用停止中なら ErrDeactivated（変更を伴う操作で使う）
+func (u *Usecase) authenticateActiveOwner(pathUserID string, cred Credential) (*principal, error) {
+	p, err := u.authenticateOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	if p.user.Deactivated() {
+		return nil, ErrDeactivated
+	}
+	return p, nil
+}
+
+// authenticateActive: authenticate に加え、利用停止中なら ErrDeactivated（本人のパスを伴わない変更で使う）
+func (u *Usecase) authenticateActive(cred Credential) (*principal, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, err
+	}
+	if p.user.Deactivated() {
+		return nil, ErrDeactivated
+	}
+	return p, nil
+}
+
+// authenticatePassword: userID/pw を検証する。未存在の場合もダミーのハッシュ比較を行い、
+// 存在するユーザーと同程度の時間をかけてから ErrAuthFailed を返す
+func (u *Usecase) authenticatePassword(userID, password string, client ClientInfo) (*domain.User, error) {
+	rec, err := u.Repo.FindByID(u.Tenant, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			if err := u.hash(func() { domain.VerifyDummyPassword(password) }); err != nil {
+				return nil, err
+			}
+			return nil, ErrAuthFailed
+		}
+		return nil, err
+	}
+	d := toDomain(rec)
+	ok, err := u.verifyPassword(d, password)
+	if err != nil {
+		return nil, err
+	}
+	u.recordLogin(d, ok, client)
+	if !ok {
+		return nil, ErrAuthFailed
+	}
+	return d, nil
+}
+
+// authenticateSession: Bearer トークンからセッションを引き、最終利用日時・接続元を更新する
+func (u *Usecase) authenticateSession(cred Credential) (*principal, error) {
+	sess, err := u.Sessions.FindByTokenHash(hashToken(cred.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrAuthFailed
+		}
+		return nil, err
+	}
+	now := u.now()
+	if !now.Before(sess.ExpiresAt) {
+		_ = u.Sessions.Delete(sess.UserID, sess.ID)
+		return nil, ErrAuthFailed
+	}
+	rec, err := u.Repo.FindByID(u.Tenant, sess.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrAuthFailed
+		}
+		return nil, err
+	}
+	if err := u.Sessions.Touch(sess.ID, now, cred.Client.IP, cred.Client.UserAgent); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			// 並行して失効した
+			return nil, ErrAuthFailed
+		}
+		return nil, err
+	}
+	p := &principal{user: toDomain(rec), sessionID: sess.ID, actorID: sess.ImpersonatorID}
+	if p.impersonating() {
+		// なりすましセッションでのリクエストはすべて本人の履歴に残す
+		u.recordFor(p, domain.ActivityImpersonationAccess, domain.ActivitySuccess, cred.Client)
+	}
+	return p, nil
+}
+
+// authenticateCertificate: クライアント証明書に対応付けられたユーザーが存在すれば認証済みとする
+func (u *Usecase) authenticateCertificate(cred Credential) (*principal, error) {
+	rec, err := u.Repo.FindByID(u.Tenant, cred.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrAuthFailed
+		}
+		return nil, err
+	}
+	return &principal{user: toDomain(rec)}, nil
+}
+
+func (u *Usecase) verifyPassword(d *domain.User, password string) (bool, error) {
+	var ok bool
+	if err := u.hash(func() { ok = d.VerifyPassword(password) }); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// hash: bcrypt 処理を Hashing 経由で実行する。混雑で実行できなかった場合は ErrBusy
+func (u *Usecase) hash(fn func()) error {
+	if u.Hashing == nil {
+		fn()
+		return nil
+	}
+	if err := u.Hashing.Do(fn); err != nil {
+		return ErrBusy
+	}
+	return nil
+}
+
+// newToken: URL に埋め込める n バイトのランダム文字列
+func newToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newID: 公開してよい識別子（セッション ID など）
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken: 保存用のトークンのハッシュ値
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,151 @@
+package usecase_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// 許容する処理時間の差（中央値の比）。bcrypt の比較が大半を占めるため、実装が同じなら十分に収まる
+const timingTolerance = 0.3
+
+// medianDuration: fn を n 回実行した所要時間の中央値
+func medianDuration(n int, fn func()) time.Duration {
+	d := make([]time.Duration, n)
+	for i := range d {
+		start := time.Now()
+		fn()
+		d[i] = time.Since(start)
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	return d[n/2]
+}
+
+func assertSimilarDuration(t *testing.T, a, b time.Duration, what string) {
+	t.Helper()
+	ratio := float64(a) / float64(b)
+	if ratio < 1-timingTolerance || ratio > 1/(1-timingTolerance) {
+		t.Errorf("%s: %v vs %v (ratio %.2f), want within %.0f%%", what, a, b, ratio, timingTolerance*100)
+	}
+}
+
+func TestAuthenticationTimingUnknownUserMatchesWrongPassword(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures bcrypt timing")
+	}
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	unknown := func() {
+		if _, err := uc.GetUser("NoSuchUser", basic("NoSuchUser", "PaSSwd4TY")); !errors.Is(err, usecase.ErrAuthFailed) {
+			t.Fatalf("unknown user: err = %v, want ErrAuthFailed", err)
+		}
+	}
+	wrong := func() {
+		if _, err := uc.GetUser("TaroYamada", basic("TaroYamada", "WrongPass1")); !errors.Is(err, usecase.ErrAuthFailed) {
+			t.Fatalf("wrong password: err = %v, want ErrAuthFailed", err)
+		}
+	}
+	// 初回の準備コストを除く
+	unknown()
+	wrong()
+	assertSimilarDuration(t, medianDuration(7, unknown), medianDuration(7, wrong), "unknown user vs wrong password")
+}
+
+// 本人のみの操作（authenticateOwner）は未存在を 404 で返すが、応答時間は誤ったパスワードと同程度にする
+func TestOwnerAuthenticationTimingUnknownUserMatchesWrongPassword(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures bcrypt timing")
+	}
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	nickname := "Taro"
+
+	endpoints := map[string]func(userID, password string) error{
+		"UpdateUser": func(userID, password string) error {
+			_, err := uc.UpdateUser(userID, basic(userID, password), domain.ProfileUpdate{Nickname: &nickname}, true)
+			return err
+		},
+		"GetPrivacy": func(userID, password string) error {
+			_, err := uc.GetPrivacy(userID, basic(userID, password))
+			return err
+		},
+	}
+	for name, call := range endpoints {
+		t.Run(name, func(t *testing.T) {
+			unknown := func() {
+				if err := call("NoSuchUser", "PaSSwd4TY"); !errors.Is(err, usecase.ErrNotFound) {
+					t.Fatalf("unknown user: err = %v, want ErrNotFound", err)
+				}
+			}
+			wrong := func() {
+				if err := call("TaroYamada", "WrongPass1"); !errors.Is(err, usecase.ErrAuthFailed) {
+					t.Fatalf("wrong password: err = %v, want ErrAuthFailed", err)
+				}
+			}
+			unknown()
+			wrong()
+			assertSimilarDuration(t, medianDuration(7, unknown), medianDuration(7, wrong), "unknown user vs wrong password")
+		})
+	}
+}
+
+func TestSignUpConcealExistingUsers(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	if _, err := uc.SignUp("TaroYamada", "Other4TY!"); !isValidation(err, usecase.ValidationReasonUserAlreadyExists) {
+		t.Fatalf("without conceal: err = %v, want user_already_exists", err)
+	}
+
+	uc.ConcealExistingUsers = true
+	got, err := uc.SignUp("TaroYamada", "Other4TY!")
+	if err != nil {
+		t.Fatalf("with conceal: err = %v, want success response", err)
+	}
+	if got.UserID != "TaroYamada" {
+		t.Errorf("with conceal: got %+v, want the same response as a new account", got)
+	}
+	// 既存のアカウントは変わらない
+	if _, err := uc.GetUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY")); err != nil {
+		t.Errorf("existing account: %v", err)
+	}
+	if _, err := uc.GetUser("TaroYamada", basic("TaroYamada", "Other4TY!")); !errors.Is(err, usecase.ErrAuthFailed) {
+		t.Errorf("concealed signup must not change the password: err = %v", err)
+	}
+}
+
+func TestSignUpConcealTimingMatchesNewAccount(t *testing.T) {
+	if testing.Short() {
+		t.Skip("measures bcrypt timing")
+	}
+	uc, _ := newTestUsecase(t)
+	uc.ConcealExistingUsers = true
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	n := 0
+	fresh := func() {
+		n++
+		if _, err := uc.SignUp(fmt.Sprintf("NewUser%04d", n), "PaSSwd4TY"); err != nil {
+			t.Fatalf("new account: %v", err)
+		}
+	}
+	existing := func() {
+		if _, err := uc.SignUp("TaroYamada", "PaSSwd4TY"); err != nil {
+			t.Fatalf("existing account: %v", err)
+		}
+	}
+	fresh()
+	existing()
+	assertSimilarDuration(t, medianDuration(7, existing), medianDuration(7, fresh), "existing vs new signup")
+}
+
+func isValidation(err error, reason usecase.ValidationReason) bool {
+	var vErr *usecase.ValidationError
+	return errors.As(err, &vErr) && vErr.Reason == reason
+}
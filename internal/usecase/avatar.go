@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"accountapi/internal/domain"
+)
+
+// SetAvatar: 本人のみ。画像を再エンコードしてサムネイルとともに保存し、以前のアバターを削除する。
+// 画像の処理は ImageProcessing で実行し、混雑している場合は ErrBusy
+func (u *Usecase) SetAvatar(pathUserID string, cred Credential, data []byte) (*domain.User, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	var images *domain.AvatarImages
+	if err := u.processImage(func() { images, err = domain.ProcessAvatar(data) }); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, mapValidationError(err)
+	}
+	avatarID, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	d := p.user
+	if err := u.Blobs.Put(avatarKey(d.UserID, avatarID, 0), images.Original); err != nil {
+		return nil, err
+	}
+	for size, b := range images.Thumbnails {
+		if err := u.Blobs.Put(avatarKey(d.UserID, avatarID, size), b); err != nil {
+			return nil, err
+		}
+	}
+	previous := d.AvatarID
+	now := u.now()
+	if err := u.Repo.UpdateAvatar(u.Tenant, d.UserID, avatarID, now); err != nil {
+		_ = u.Blobs.DeletePrefix(avatarPrefix(d.UserID, avatarID))
+		return nil, mapRepoNotFound(err)
+	}
+	if previous != "" {
+		_ = u.Blobs.DeletePrefix(avatarPrefix(d.UserID, previous))
+	}
+	d.AvatarID = avatarID
+	d.UpdatedAt = now
+	u.recordFor(p, domain.ActivityAvatarChange, domain.ActivitySuccess, cred.Client)
+	return d, nil
+}
+
+// DeleteAvatar: 本人のみ。アップロード済みのアバターを削除する
+func (u *Usecase) DeleteAvatar(pathUserID string, cred Credential) (*domain.User, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	d := p.user
+	if d.AvatarID == "" {
+		return d, nil
+	}
+	now := u.now()
+	if err := u.Repo.UpdateAvatar(u.Tenant, d.UserID, "", now); err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	_ = u.Blobs.DeletePrefix(avatarPrefix(d.UserID, d.AvatarID))
+	d.AvatarID = ""
+	d.UpdatedAt = now
+	u.recordFor(p, domain.ActivityAvatarChange, domain.ActivitySuccess, cred.Client)
+	return d, nil
+}
+
+// AvatarImage: アバター画像（PNG）と版
+type AvatarImage struct {
+	Data    []byte
+	Version string
+	// Public: プロフィールを公開しており、認証なしでも見える（共有キャッシュに載せてよい）
+	Public bool
+}
+
+// Avatar: 閲覧者から見えるユーザーのアバター画像を返す。size は 0（元画像）または AvatarThumbnailSizes のいずれか。
+// cred が nil の場合は匿名の閲覧者として、プロフィールを公開しているユーザーのみ返す。
+// 利用停止・ブロック・公開範囲により見えない場合は、存在しない場合と同じく ErrNotFound
+func (u *Usecase) Avatar(userID string, cred *Credential, size int) (*AvatarImage, error) {
+	if size != 0 && !slices.Contains(domain.AvatarThumbnailSizes, size) {
+		return nil, ErrNotFound
+	}
+	viewerID := ""
+	if cred != nil {
+		p, err := u.authenticate(*cred)
+		if err != nil {
+			return nil, err
+		}
+		viewerID = p.user.UserID
+	}
+	rec, err := u.Repo.FindByID(u.Tenant, userID)
+	if err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	public := rec.DeactivatedAt.IsZero() && rec.Privacy.ProfileVisibility().VisibleTo(domain.Viewer{})
+	switch {
+	case viewerID == "":
+		if !public {
+			return nil, ErrNotFound
+		}
+	case viewerID != rec.UserID:
+		// GET /users/{user_id} と同じく、見えないユーザーは存在を明かさない
+		if _, err := u.findVisible(rec.UserID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+	if rec.AvatarID == "" {
+		return nil, ErrNotFound
+	}
+	data, err := u.Blobs.Get(avatarKey(rec.UserID, rec.AvatarID, size))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &AvatarImage{Data: data, Version: rec.AvatarID, Public: public}, nil
+}
+
+// processImage: 画像の処理を ImageProcessing 経由で実行する。混雑で実行できなかった場合は ErrBusy
+func (u *Usecase) processImage(fn func()) error {
+	if u.ImageProcessing == nil {
+		fn()
+		return nil
+	}
+	if err := u.ImageProcessing.Do(fn); err != nil {
+		return ErrBusy
+	}
+	return nil
+}
+
+func avatarPrefix(userID, avatarID string) string {
+	if avatarID == "" {
+		return "avatars/" + userID
+	}
+	return "avatars/" + userID + "/" + avatarID
+}
+
+func avatarKey(userID, avatarID string, size int) string {
+	if size == 0 {
+		return avatarPrefix(userID, avatarID) + "/original.png"
+	}
+	return fmt.Sprintf("%s/%d.png", avatarPrefix(userID, avatarID), size)
+}
+
+// Identicon: user_id から生成した identicon（PNG または SVG）。ユーザーの有無は確認しない。
+// PNG の生成は ImageProcessing で実行し、混雑している場合は ErrBusy
+func (u *Usecase) Identicon(userID string, size int, svg bool) ([]byte, error) {
+	if size == 0 {
+		size = domain.IdenticonDefaultSize
+	}
+	if size < domain.IdenticonMinSize || size > domain.IdenticonMaxSize {
+		return nil, &ValidationError{Reason: ValidationReasonIdenticonSize}
+	}
+	ic := domain.NewIdenticon(userID)
+	if svg {
+		return ic.SVG(size), nil
+	}
+	var (
+		data   []byte
+		pngErr error
+	)
+	if err := u.processImage(func() { data, pngErr = ic.PNG(size) }); err != nil {
+		return nil, err
+	}
+	return data, pngErr
+}
@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAvatarFollowsProfileVisibility(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	owner, viewer := basic("TaroYamada", "PaSSwd4TY"), basic("HanakoSato", "PaSSwd4HS")
+	if _, err := uc.SetAvatar("TaroYamada", owner, testPNG(t)); err != nil {
+		t.Fatalf("SetAvatar: %v", err)
+	}
+
+	check := func(name string, cred *usecase.Credential, wantOK bool) {
+		t.Helper()
+		_, err := uc.Avatar("TaroYamada", cred, 0)
+		if wantOK && err != nil {
+			t.Errorf("%s: err = %v, want the avatar", name, err)
+		}
+		if !wantOK && !errors.Is(err, usecase.ErrNotFound) {
+			t.Errorf("%s: err = %v, want ErrNotFound", name, err)
+		}
+	}
+	check("anonymous, public", nil, true)
+	check("viewer, public", &viewer, true)
+
+	if _, err := uc.SetPrivacy("TaroYamada", owner, domain.VisibilityPrivate, nil); err != nil {
+		t.Fatalf("SetPrivacy: %v", err)
+	}
+	check("anonymous, private", nil, false)
+	check("viewer, private", &viewer, false)
+	check("owner, private", &owner, true)
+
+	if _, err := uc.SetPrivacy("TaroYamada", owner, domain.VisibilityPublic, nil); err != nil {
+		t.Fatalf("SetPrivacy: %v", err)
+	}
+	if _, err := uc.Block("TaroYamada", owner, "HanakoSato"); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	check("blocked viewer", &viewer, false)
+
+	// 見えない場合は存在しないユーザーと区別できない
+	if _, err := uc.Avatar("NoSuchUser", nil, 0); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("unknown user: err = %v, want ErrNotFound", err)
+	}
+}
+
+// busyExecutor: 常に混雑している Executor
+type busyExecutor struct{}
+
+func (busyExecutor) Do(fn func()) error { return errors.New("saturated") }
+
+func TestSetAvatarBusyWhenImageProcessingIsSaturated(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.ImageProcessing = busyExecutor{}
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	if _, err := uc.SetAvatar("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), testPNG(t)); !errors.Is(err, usecase.ErrBusy) {
+		t.Fatalf("err = %v, want ErrBusy", err)
+	}
+	if _, err := uc.Avatar("TaroYamada", nil, 0); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("Avatar: err = %v, want no avatar saved", err)
+	}
+}
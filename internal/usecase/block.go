@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"errors"
+
+	"accountapi/internal/domain"
+)
+
+// Block: 本人が targetID をブロックする（ブロック済みでも成功）。ブロックされたユーザーからはプロフィールが見えなくなり、フォローも解除される。
+// 本人から見えないユーザーは、存在しない場合と区別できないよう ErrNotFound
+func (u *Usecase) Block(pathUserID string, cred Credential, targetID string) (*domain.BlockRecord, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	if targetID == p.user.UserID {
+		return nil, &ValidationError{Reason: ValidationReasonBlockSelf}
+	}
+	if _, err := u.findVisible(targetID, p.user.UserID); err != nil {
+		return nil, err
+	}
+	rec := &domain.BlockRecord{OwnerID: p.user.UserID, TargetID: targetID, CreatedAt: u.now()}
+	if err := u.Blocks.Add(rec); err != nil && !errors.Is(err, domain.ErrAlreadyExists) {
+		return nil, err
+	}
+	// ブロックした相手からのフォローは解除する
+	if err := u.Follows.Remove(targetID, p.user.UserID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Unblock: 本人のブロックを解除する（ブロックしていなければ ErrNotFound）
+func (u *Usecase) Unblock(pathUserID string, cred Credential, targetID string) error {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return err
+	}
+	return mapRepoNotFound(u.Blocks.Remove(p.user.UserID, targetID))
+}
+
+// ListBlocks: 本人のブロック一覧（既定はブロックした古い順）
+func (u *Usecase) ListBlocks(pathUserID string, cred Credential, order UserSort) ([]*domain.BlockRecord, error) {
+	p, err := u.authenticateOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	list, err := u.Blocks.ListByOwner(p.user.UserID)
+	if err != nil || order.Key == UserSortDefault {
+		return list, err
+	}
+	users := make([]*domain.UserRecord, 0, len(list))
+	blocks := list[:0]
+	for _, b := range list {
+		rec, err := u.Repo.FindByID(u.Tenant, b.TargetID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		blocks = append(blocks, b)
+		users = append(users, rec)
+	}
+	sortUsers(blocks, users, order)
+	return blocks, nil
+}
@@ -0,0 +1,37 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// 見えないユーザーのブロックは、存在しないユーザーと同じく ErrNotFound
+func TestBlockDoesNotRevealHiddenUsers(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	taro, hanako := basic("TaroYamada", "PaSSwd4TY"), basic("HanakoSato", "PaSSwd4HS")
+
+	if _, err := uc.Block("TaroYamada", taro, "NoSuchUser"); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("unknown user: err = %v, want ErrNotFound", err)
+	}
+	if _, err := uc.SetPrivacy("HanakoSato", hanako, domain.VisibilityPrivate, nil); err != nil {
+		t.Fatalf("SetPrivacy: %v", err)
+	}
+	if _, err := uc.Block("TaroYamada", taro, "HanakoSato"); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("private user: err = %v, want ErrNotFound", err)
+	}
+	if blocks, err := uc.ListBlocks("TaroYamada", taro, usecase.UserSort{}); err != nil || len(blocks) != 0 {
+		t.Errorf("blocks = %v, %v, want none", blocks, err)
+	}
+
+	if _, err := uc.SetPrivacy("HanakoSato", hanako, domain.VisibilityPublic, nil); err != nil {
+		t.Fatalf("SetPrivacy: %v", err)
+	}
+	if _, err := uc.Block("TaroYamada", taro, "HanakoSato"); err != nil {
+		t.Errorf("public user: %v", err)
+	}
+}
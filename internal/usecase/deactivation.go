@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// Deactivate: 本人のアカウントを利用停止にする。プロフィールは他ユーザーから見えなくなり、変更もできなくなる。
+// セッションはすべて無効になり、再開にはあらためてログインして Reactivate する
+func (u *Usecase) Deactivate(pathUserID string, cred Credential) (*domain.User, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	if p.impersonating() {
+		return nil, ErrImpersonationForbidden
+	}
+	now := u.now()
+	if err := u.Repo.UpdateDeactivation(u.Tenant, p.user.UserID, true, now); err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	if err := u.Sessions.DeleteByUser(p.user.UserID); err != nil {
+		return nil, err
+	}
+	d := p.user
+	d.DeactivatedAt, d.UpdatedAt = now, now
+	u.recordFor(p, domain.ActivityDeactivate, domain.ActivitySuccess, cred.Client)
+	return d, nil
+}
+
+// Reactivate: 利用停止中のアカウントを本人が再開する（利用中なら何もしない）
+func (u *Usecase) Reactivate(pathUserID string, cred Credential) (*domain.User, error) {
+	p, err := u.authenticateOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	if p.impersonating() {
+		return nil, ErrImpersonationForbidden
+	}
+	d := p.user
+	if !d.Deactivated() {
+		return d, nil
+	}
+	now := u.now()
+	if err := u.Repo.UpdateDeactivation(u.Tenant, d.UserID, false, now); err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	d.DeactivatedAt, d.UpdatedAt = time.Time{}, now
+	u.recordFor(p, domain.ActivityReactivate, domain.ActivitySuccess, cred.Client)
+	return d, nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// DefaultEmailVerificationTTL: EmailVerificationTTL 未指定時の確認トークンの有効期間
+const DefaultEmailVerificationTTL = 24 * time.Hour
+
+// DefaultEmailResendInterval: EmailResendInterval 未指定時の、確認メールを再び送れるまでの間隔
+const DefaultEmailResendInterval = time.Minute
+
+// RateLimitedError: 短い間隔で繰り返された。RetryAfter の後に再試行できる
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string { return "rate limited" }
+
+// Mailer: メール送信
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// SetEmail: 本人認証し、メールアドレスを設定して確認メールを送る（空文字で削除）
+func (u *Usecase) SetEmail(pathUserID string, cred Credential, email string) (*domain.User, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	// 連絡先の変更はアカウントの乗っ取りにつながるため、なりすまし中は不可
+	if p.impersonating() {
+		return nil, ErrImpersonationForbidden
+	}
+	d := p.user
+	before, verified := d.Email, d.EmailVerified
+	if err := d.SetEmail(email); err != nil {
+		return nil, mapValidationError(err)
+	}
+	now := u.now()
+	if d.Email == "" {
+		if err := u.Repo.UpdateEmail(u.Tenant, d.UserID, "", "", time.Time{}, now); err != nil {
+			return nil, mapRepoNotFound(err)
+		}
+		d.UpdatedAt = now
+		u.record(d.UserID, domain.ActivityEmailChange, domain.ActivitySuccess, cred.Client)
+		return d, nil
+	}
+	// 確認済みの同じアドレスなら何もしない
+	if d.Email == before && verified {
+		return d, nil
+	}
+	// 他ユーザーが確認済みのアドレスには送らない（保存時にも UpdateEmail が確かめる）
+	if err := u.Repo.CheckEmail(u.Tenant, d.UserID, d.Email); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, &ValidationError{Reason: ValidationReasonEmailAlreadyUsed}
+		}
+		return nil, mapRepoNotFound(err)
+	}
+	// 任意のアドレスへの送信の繰り返しを防ぐため、ユーザーごとに送信の間隔を空ける
+	rec, err := u.Repo.FindByID(u.Tenant, d.UserID)
+	if err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	if next := rec.EmailSentAt.Add(u.emailResendInterval()); !rec.EmailSentAt.IsZero() && now.Before(next) {
+		return nil, &RateLimitedError{RetryAfter: next.Sub(now)}
+	}
+	token, err := newToken(32)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := now.Add(u.emailVerificationTTL())
+	// 送信できなかった場合にアドレスだけ変わらないよう、保存より先に送る。
+	// 保存に失敗した場合、送ったトークンは保存されないため使えない
+	if err := u.Mailer.Send(d.Email, "メールアドレスの確認 / Verify your email address", verificationMailBody(u.Tenant, d.UserID, token, expiresAt)); err != nil {
+		return nil, fmt.Errorf("send verification mail: %w", err)
+	}
+	if err := u.Repo.UpdateEmail(u.Tenant, d.UserID, d.Email, hashToken(token), expiresAt, now); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, &ValidationError{Reason: ValidationReasonEmailAlreadyUsed}
+		}
+		return nil, mapRepoNotFound(err)
+	}
+	d.UpdatedAt = now
+	u.record(d.UserID, domain.ActivityEmailChange, domain.ActivitySuccess, cred.Client)
+	return d, nil
+}
+
+// VerifyEmail: 確認トークンを検証してメールアドレスを確認済みにする。
+// ユーザーの有無を区別できないよう、失敗はすべて ValidationReasonEmailTokenInvalid
+func (u *Usecase) VerifyEmail(pathUserID, token string, client ClientInfo) (*domain.User, error) {
+	invalid := &ValidationError{Reason: ValidationReasonEmailTokenInvalid}
+	rec, err := u.Repo.FindByID(u.Tenant, pathUserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	if token == "" || rec.Email == "" || rec.EmailVerified || rec.EmailTokenHash == "" || !rec.DeactivatedAt.IsZero() {
+		return nil, invalid
+	}
+	now := u.now()
+	if !now.Before(rec.EmailTokenExpiresAt) {
+		return nil, invalid
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(rec.EmailTokenHash)) != 1 {
+		return nil, invalid
+	}
+	if err := u.Repo.MarkEmailVerified(u.Tenant, rec.UserID, rec.Email, now); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, &ValidationError{Reason: ValidationReasonEmailAlreadyUsed}
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, invalid
+		}
+		return nil, err
+	}
+	u.record(rec.UserID, domain.ActivityEmailVerify, domain.ActivitySuccess, client)
+	d := toDomain(rec)
+	d.EmailVerified = true
+	d.UpdatedAt = now
+	return d, nil
+}
+
+func (u *Usecase) emailResendInterval() time.Duration {
+	if u.EmailResendInterval > 0 {
+		return u.EmailResendInterval
+	}
+	return DefaultEmailResendInterval
+}
+
+func (u *Usecase) emailVerificationTTL() time.Duration {
+	if u.EmailVerificationTTL > 0 {
+		return u.EmailVerificationTTL
+	}
+	return DefaultEmailVerificationTTL
+}
+
+func verificationMailBody(tenant, userID, token string, expiresAt time.Time) string {
+	body := fmt.Sprintf(`%s さん
+
+以下のトークンを POST /users/%s/email/verify に送信して、メールアドレスの確認を完了してください。
+To verify your email address, send the token below to POST /users/%s/email/verify.
+
+{"token": "%s"}
+
+有効期限 / Expires at: %s
+`, userID, userID, userID, token, expiresAt.UTC().Format(time.RFC3339))
+	if tenant != domain.DefaultTenant {
+		body += fmt.Sprintf("テナント / Tenant: %s\n", tenant)
+	}
+	return body
+}
+
+func mapRepoNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package usecase_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"accountapi/internal/infrastructure/mailer"
+	"accountapi/internal/usecase"
+)
+
+var reMailToken = regexp.MustCompile(`\{"token": "([^"]+)"\}`)
+
+func TestSetEmailSendsVerificationToken(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	sink := mailer.NewSink()
+	uc.Mailer = sink
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	if _, err := uc.SetEmail("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "taro@example.com"); err != nil {
+		t.Fatalf("SetEmail: %v", err)
+	}
+	msg, ok := sink.Last("taro@example.com")
+	if !ok {
+		t.Fatal("no verification mail sent")
+	}
+	m := reMailToken.FindStringSubmatch(msg.Body)
+	if m == nil {
+		t.Fatalf("no token in mail body:\n%s", msg.Body)
+	}
+	d, err := uc.VerifyEmail("TaroYamada", m[1], usecase.ClientInfo{})
+	if err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if d.Email != "taro@example.com" || !d.EmailVerified {
+		t.Errorf("got email %q verified=%v, want verified taro@example.com", d.Email, d.EmailVerified)
+	}
+}
+
+type failingMailer struct{}
+
+func (failingMailer) Send(to, subject, body string) error { return errors.New("smtp unavailable") }
+
+func TestSetEmailKeepsAddressWhenSendFails(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+	if _, err := uc.SetEmail("TaroYamada", cred, "taro@example.com"); err != nil {
+		t.Fatalf("SetEmail: %v", err)
+	}
+
+	clock.Advance(usecase.DefaultEmailResendInterval)
+	uc.Mailer = failingMailer{}
+	if _, err := uc.SetEmail("TaroYamada", cred, "new@example.com"); err == nil {
+		t.Fatal("SetEmail succeeded although the mail could not be sent")
+	}
+	rec, err := uc.Repo.FindByID(uc.Tenant, "TaroYamada")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if rec.Email != "taro@example.com" {
+		t.Errorf("email = %q, want the previous address kept", rec.Email)
+	}
+}
+
+// verifyEmail: sink に届いた確認メールのトークンでアドレスを確認済みにする
+func verifyEmail(t *testing.T, uc *usecase.Usecase, sink *mailer.Sink, userID, email string) {
+	t.Helper()
+	msg, ok := sink.Last(email)
+	if !ok {
+		t.Fatalf("no verification mail to %s", email)
+	}
+	m := reMailToken.FindStringSubmatch(msg.Body)
+	if m == nil {
+		t.Fatalf("no token in mail body:\n%s", msg.Body)
+	}
+	if _, err := uc.VerifyEmail(userID, m[1], usecase.ClientInfo{}); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+}
+
+func TestSetEmailDoesNotMailAddressVerifiedByOthers(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	sink := mailer.NewSink()
+	uc.Mailer = sink
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	if _, err := uc.SetEmail("HanakoSato", basic("HanakoSato", "PaSSwd4HS"), "hanako@example.com"); err != nil {
+		t.Fatalf("SetEmail: %v", err)
+	}
+	verifyEmail(t, uc, sink, "HanakoSato", "hanako@example.com")
+	sent := len(sink.Messages())
+
+	_, err := uc.SetEmail("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "Hanako@example.com")
+	if !isValidation(err, usecase.ValidationReasonEmailAlreadyUsed) {
+		t.Fatalf("err = %v, want email_already_used", err)
+	}
+	if got := len(sink.Messages()); got != sent {
+		t.Errorf("%d mails sent to an address verified by another user", got-sent)
+	}
+}
+
+func TestSetEmailThrottlesResends(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	uc.EmailResendInterval = time.Minute
+	sink := mailer.NewSink()
+	uc.Mailer = sink
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+
+	if _, err := uc.SetEmail("TaroYamada", cred, "taro@example.com"); err != nil {
+		t.Fatalf("SetEmail: %v", err)
+	}
+	clock.Advance(20 * time.Second)
+	var limited *usecase.RateLimitedError
+	if _, err := uc.SetEmail("TaroYamada", cred, "other@example.com"); !errors.As(err, &limited) {
+		t.Fatalf("err = %v, want RateLimitedError", err)
+	}
+	if limited.RetryAfter != 40*time.Second {
+		t.Errorf("RetryAfter = %v, want 40s", limited.RetryAfter)
+	}
+	if len(sink.Messages()) != 1 {
+		t.Errorf("mails sent = %d, want 1", len(sink.Messages()))
+	}
+
+	clock.Advance(40 * time.Second)
+	if _, err := uc.SetEmail("TaroYamada", cred, "other@example.com"); err != nil {
+		t.Fatalf("SetEmail after the interval: %v", err)
+	}
+	if _, ok := sink.Last("other@example.com"); !ok {
+		t.Error("no mail sent after the interval")
+	}
+}
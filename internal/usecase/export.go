@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// DefaultExportTTL: ExportTTL 未指定時の、書き出したアーカイブをダウンロードできる期間
+const DefaultExportTTL = 24 * time.Hour
+
+var (
+	ErrExportNotReady = errors.New("export not ready") // 409
+	ErrExportExpired  = errors.New("export expired")   // 410
+)
+
+// ExportData: 書き出す個人データ。パスワードやトークンのハッシュ値は含めない
+type ExportData struct {
+	GeneratedAt time.Time
+	User        *domain.User
+	Activity    []*domain.ActivityRecord
+	Sessions    []*Session
+	Followers   []*domain.FollowRecord
+	Following   []*domain.FollowRecord
+	Blocks      []*domain.BlockRecord
+	Revisions   []*domain.ProfileRevision
+	Groups      []*UserGroup
+	// PendingChanges: 審査待ちのプロフィール変更
+	PendingChanges []*domain.ModerationRecord
+	// Avatar: アップロード済みのアバター（元画像、PNG）。未設定なら nil
+	Avatar []byte
+}
+
+// Archiver: ExportData をダウンロード用の 1 ファイルにまとめる
+type Archiver interface {
+	Archive(data *ExportData) ([]byte, error)
+}
+
+// RequestExport: 本人の個人データの書き出しを受け付け、バックグラウンドで作成する。
+// 作成中のものがあれば新たには作らずそれを返す
+func (u *Usecase) RequestExport(pathUserID string, cred Credential) (*domain.ExportRecord, error) {
+	p, err := u.authenticateOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	// 本人の求めに応じるものなので、なりすまし中は不可
+	if p.impersonating() {
+		return nil, ErrImpersonationForbidden
+	}
+	userID := p.user.UserID
+	now := u.now()
+	recs, err := u.Exports.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range recs {
+		if rec.Status == domain.ExportPending {
+			return rec, nil
+		}
+		// 期限切れ・失敗したものはここで片付ける
+		if rec.Expired(now) || rec.Status == domain.ExportFailed {
+			u.deleteExport(rec)
+		}
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	rec := &domain.ExportRecord{ID: id, UserID: userID, Status: domain.ExportPending, CreatedAt: now}
+	if err := u.Exports.Create(rec); err != nil {
+		return nil, err
+	}
+	u.recordFor(p, domain.ActivityDataExport, domain.ActivitySuccess, cred.Client)
+	c := *rec
+	go u.buildExport(&c)
+	return rec, nil
+}
+
+// GetExport: 本人の書き出しの状態。期限切れなら Status を ExportExpired にして返す
+func (u *Usecase) GetExport(pathUserID string, cred Credential, id string) (*domain.ExportRecord, error) {
+	p, err := u.authenticateOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := u.Exports.Find(p.user.UserID, id)
+	if err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	if rec.Expired(u.now()) {
+		// 期限切れのアーカイブは残さない（状態を示すため要求自体は次の書き出し要求まで残す）
+		_ = u.Blobs.DeletePrefix(exportPrefix(rec.ID))
+		c := *rec
+		c.Status = domain.ExportExpired
+		return &c, nil
+	}
+	return rec, nil
+}
+
+// ExportArchive: 作成済みのアーカイブ。作成中は ErrExportNotReady、期限切れは ErrExportExpired
+func (u *Usecase) ExportArchive(pathUserID string, cred Credential, id string) ([]byte, *domain.ExportRecord, error) {
+	rec, err := u.GetExport(pathUserID, cred, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch rec.Status {
+	case domain.ExportReady:
+	case domain.ExportExpired:
+		return nil, nil, ErrExportExpired
+	default:
+		return nil, nil, ErrExportNotReady
+	}
+	data, err := u.Blobs.Get(exportKey(rec.ID))
+	if err != nil {
+		return nil, nil, mapRepoNotFound(err)
+	}
+	return data, rec, nil
+}
+
+// buildExport: アーカイブを作成して BlobStore に保存し、状態を更新する
+func (u *Usecase) buildExport(rec *domain.ExportRecord) {
+	data, err := u.exportData(rec.UserID)
+	var archive []byte
+	if err == nil {
+		archive, err = u.Archiver.Archive(data)
+	}
+	if err == nil {
+		err = u.Blobs.Put(exportKey(rec.ID), archive)
+	}
+	if err != nil {
+		log.Printf("export %s: %v", rec.ID, err)
+	}
+	// 作成中に user_id が変更されていることがあるため、保存されているものを読み直して状態だけを書き換える。
+	// 作成中に退会された場合は ErrNotFound になるため、アーカイブも消す
+	cur, findErr := u.Exports.FindByID(rec.ID)
+	if findErr == nil {
+		cur.CompletedAt = u.now()
+		if err != nil {
+			cur.Status = domain.ExportFailed
+		} else {
+			cur.Status = domain.ExportReady
+			cur.ExpiresAt = cur.CompletedAt.Add(u.exportTTL())
+			cur.Size = len(archive)
+		}
+		findErr = u.Exports.Update(cur)
+	}
+	if findErr != nil {
+		_ = u.Blobs.DeletePrefix(exportPrefix(rec.ID))
+	}
+}
+
+// SweepExports: 期限切れのアーカイブと、書き出しの記録が残っていないアーカイブ（再起動前のものなど）を削除する。
+// 期限切れの記録は状態を示すため次の書き出し要求まで残す
+func (u *Usecase) SweepExports() error {
+	ids, err := u.Blobs.List(exportPrefix(""))
+	if err != nil {
+		return err
+	}
+	now := u.now()
+	var errs []error
+	for _, id := range ids {
+		rec, err := u.Exports.FindByID(id)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+		case err != nil:
+			errs = append(errs, err)
+			continue
+		case !rec.Expired(now):
+			continue
+		}
+		if err := u.Blobs.DeletePrefix(exportPrefix(id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (u *Usecase) exportData(userID string) (*ExportData, error) {
+	rec, err := u.Repo.FindByID(u.Tenant, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+	d := toDomain(rec)
+	d.PasswordHash = ""
+	out := &ExportData{GeneratedAt: u.now(), User: d}
+	if out.Activity, err = u.Activity.List(userID, 0, 0, out.GeneratedAt); err != nil {
+		return nil, fmt.Errorf("activity: %w", err)
+	}
+	sessions, err := u.Sessions.ListByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("sessions: %w", err)
+	}
+	for _, s := range sessions {
+		out.Sessions = append(out.Sessions, toSession(s, ""))
+	}
+	if out.Followers, err = u.Follows.ListFollowers(userID, 0, 0); err != nil {
+		return nil, fmt.Errorf("followers: %w", err)
+	}
+	if out.Following, err = u.Follows.ListFollowing(userID, 0, 0); err != nil {
+		return nil, fmt.Errorf("following: %w", err)
+	}
+	if out.Blocks, err = u.Blocks.ListByOwner(userID); err != nil {
+		return nil, fmt.Errorf("blocks: %w", err)
+	}
+	if out.Revisions, err = u.Revisions.List(userID, 0, 0); err != nil {
+		return nil, fmt.Errorf("revisions: %w", err)
+	}
+	if out.Groups, err = u.userGroups(userID); err != nil {
+		return nil, fmt.Errorf("groups: %w", err)
+	}
+	pending, err := u.Moderation.List(0)
+	if err != nil {
+		return nil, fmt.Errorf("moderation: %w", err)
+	}
+	for _, m := range pending {
+		if m.UserID == userID {
+			out.PendingChanges = append(out.PendingChanges, m)
+		}
+	}
+	if d.AvatarID != "" {
+		if out.Avatar, err = u.Blobs.Get(avatarKey(userID, d.AvatarID, 0)); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("avatar: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// deleteExports: userID の書き出しをアーカイブとともにすべて削除する
+func (u *Usecase) deleteExports(userID string) error {
+	recs, err := u.Exports.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		if err := u.Blobs.DeletePrefix(exportPrefix(rec.ID)); err != nil {
+			return err
+		}
+	}
+	return u.Exports.DeleteByUser(userID)
+}
+
+// deleteExport: 期限切れなどのアーカイブを片付ける。失敗しても次の機会に再び試みる
+func (u *Usecase) deleteExport(rec *domain.ExportRecord) {
+	if err := u.Blobs.DeletePrefix(exportPrefix(rec.ID)); err != nil {
+		return
+	}
+	_ = u.Exports.Delete(rec.ID)
+}
+
+func exportPrefix(id string) string {
+	return "exports/" + id
+}
+
+func exportKey(id string) string {
+	return exportPrefix(id) + "/archive"
+}
+
+func (u *Usecase) exportTTL() time.Duration {
+	if u.ExportTTL > 0 {
+		return u.ExportTTL
+	}
+	return DefaultExportTTL
+}
@@ -0,0 +1,104 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// gatedArchiver: release が閉じられるまで作成を止める Archiver
+type gatedArchiver struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (a *gatedArchiver) Archive(data *usecase.ExportData) ([]byte, error) {
+	close(a.started)
+	<-a.release
+	return []byte("archive"), nil
+}
+
+// waitExport: 書き出しの作成が終わるまで待つ
+func waitExport(t *testing.T, uc *usecase.Usecase, userID, password, id string) *domain.ExportRecord {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec, err := uc.GetExport(userID, basic(userID, password), id)
+		if err != nil {
+			t.Fatalf("GetExport: %v", err)
+		}
+		if rec.Status != domain.ExportPending {
+			return rec
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("export did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestExportSurvivesRenameDuringGeneration(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	archiver := &gatedArchiver{started: make(chan struct{}), release: make(chan struct{})}
+	uc.Archiver = archiver
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	rec, err := uc.RequestExport("TaroYamada", basic("TaroYamada", "PaSSwd4TY"))
+	if err != nil {
+		t.Fatalf("RequestExport: %v", err)
+	}
+	<-archiver.started
+	if _, err := uc.RenameUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "TaroYamada2"); err != nil {
+		t.Fatalf("RenameUser: %v", err)
+	}
+	close(archiver.release)
+
+	got := waitExport(t, uc, "TaroYamada2", "PaSSwd4TY", rec.ID)
+	if got.Status != domain.ExportReady {
+		t.Fatalf("status = %s, want ready", got.Status)
+	}
+	if _, _, err := uc.ExportArchive("TaroYamada2", basic("TaroYamada2", "PaSSwd4TY"), rec.ID); err != nil {
+		t.Errorf("ExportArchive after rename: %v", err)
+	}
+}
+
+func TestSweepExports(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	uc.ExportTTL = time.Hour
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	rec, err := uc.RequestExport("TaroYamada", basic("TaroYamada", "PaSSwd4TY"))
+	if err != nil {
+		t.Fatalf("RequestExport: %v", err)
+	}
+	waitExport(t, uc, "TaroYamada", "PaSSwd4TY", rec.ID)
+	// 記録のないアーカイブ（再起動前のものなど）
+	if err := uc.Blobs.Put("exports/orphan/archive", []byte("archive")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.SweepExports(); err != nil {
+		t.Fatalf("SweepExports: %v", err)
+	}
+	if _, err := uc.Blobs.Get("exports/orphan/archive"); err == nil {
+		t.Error("orphaned archive was kept")
+	}
+	if _, err := uc.Blobs.Get("exports/" + rec.ID + "/archive"); err != nil {
+		t.Errorf("archive within its TTL was removed: %v", err)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if err := uc.SweepExports(); err != nil {
+		t.Fatalf("SweepExports: %v", err)
+	}
+	if _, err := uc.Blobs.Get("exports/" + rec.ID + "/archive"); err == nil {
+		t.Error("expired archive was kept")
+	}
+	// 記録は状態を示すため残る
+	got, err := uc.GetExport("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), rec.ID)
+	if err != nil || got.Status != domain.ExportExpired {
+		t.Errorf("GetExport = %+v, %v, want expired", got, err)
+	}
+}
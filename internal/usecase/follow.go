@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+const (
+	DefaultFollowPageSize = 20
+	MaxFollowPageSize     = 100
+)
+
+// FollowEntry: フォロー一覧の 1 件（相手の user_id とフォローした日時）
+type FollowEntry struct {
+	ID     uint64 // ページング用
+	UserID string
+	At     time.Time
+}
+
+// Follow: 本人が targetID をフォローする（フォロー済みでも成功）。相手から見えない場合は ErrNotFound
+func (u *Usecase) Follow(pathUserID string, cred Credential, targetID string) error {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return err
+	}
+	if targetID == p.user.UserID {
+		return &ValidationError{Reason: ValidationReasonFollowSelf}
+	}
+	if _, err := u.findVisible(targetID, p.user.UserID); err != nil {
+		return err
+	}
+	rec := &domain.FollowRecord{FollowerID: p.user.UserID, FolloweeID: targetID, CreatedAt: u.now()}
+	if err := u.Follows.Add(rec); err != nil && !errors.Is(err, domain.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// Unfollow: 本人のフォローを解除する（フォローしていなければ ErrNotFound）
+func (u *Usecase) Unfollow(pathUserID string, cred Credential, targetID string) error {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return err
+	}
+	return mapRepoNotFound(u.Follows.Remove(p.user.UserID, targetID))
+}
+
+// ListFollowers: pathUserID のフォロワー（既定は新しい順）。次ページがあれば次の beforeID を返す
+func (u *Usecase) ListFollowers(pathUserID string, cred Credential, beforeID uint64, limit int, order UserSort) ([]*FollowEntry, uint64, error) {
+	return u.listFollows(pathUserID, cred, beforeID, limit, order, u.Follows.ListFollowers, func(rec *domain.FollowRecord) string { return rec.FollowerID })
+}
+
+// ListFollowing: pathUserID がフォローしているユーザー（既定は新しい順）
+func (u *Usecase) ListFollowing(pathUserID string, cred Credential, beforeID uint64, limit int, order UserSort) ([]*FollowEntry, uint64, error) {
+	return u.listFollows(pathUserID, cred, beforeID, limit, order, u.Follows.ListFollowing, func(rec *domain.FollowRecord) string { return rec.FolloweeID })
+}
+
+func (u *Usecase) listFollows(
+	pathUserID string, cred Credential, beforeID uint64, limit int, order UserSort,
+	list func(userID string, beforeID uint64, limit int) ([]*domain.FollowRecord, error),
+	other func(rec *domain.FollowRecord) string,
+) ([]*FollowEntry, uint64, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, 0, err
+	}
+	viewerID := p.user.UserID
+	// GetUser と同じく、閲覧できないユーザーの一覧は存在しないものとして扱う
+	if pathUserID != viewerID {
+		if _, err := u.findVisible(pathUserID, viewerID); err != nil {
+			return nil, 0, err
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultFollowPageSize
+	}
+	if limit > MaxFollowPageSize {
+		limit = MaxFollowPageSize
+	}
+	if order.Key == UserSortDefault {
+		// 続きの有無を判定するため 1 件多く取得する
+		recs, err := list(pathUserID, beforeID, limit+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		var next uint64
+		if len(recs) > limit {
+			recs = recs[:limit]
+			next = recs[limit-1].ID
+		}
+		out, _, err := u.visibleFollows(recs, viewerID, other)
+		return out, next, err
+	}
+	// 相手のユーザーの項目で並べる場合はすべて取得して並べ替え、beforeID（前ページの最後）の次から返す
+	recs, err := list(pathUserID, 0, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	out, users, err := u.visibleFollows(recs, viewerID, other)
+	if err != nil {
+		return nil, 0, err
+	}
+	sortUsers(out, users, order)
+	if beforeID != 0 {
+		start := -1
+		for i, e := range out {
+			if e.ID == beforeID {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			// 前ページの最後がフォロー解除などで消えた場合は続きを決められない
+			return nil, 0, &ValidationError{Reason: ValidationReasonCursorInvalid}
+		}
+		out = out[start:]
+	}
+	var next uint64
+	if len(out) > limit {
+		out = out[:limit]
+		next = out[limit-1].ID
+	}
+	return out, next, nil
+}
+
+// followCounts: userID のフォロワー数・フォロー数。一覧と同じく、閲覧者から見えないユーザーは数えない
+func (u *Usecase) followCounts(userID, viewerID string) (followers, following int, err error) {
+	count := func(list func(userID string, beforeID uint64, limit int) ([]*domain.FollowRecord, error), other func(rec *domain.FollowRecord) string) (int, error) {
+		recs, err := list(userID, 0, 0)
+		if err != nil {
+			return 0, err
+		}
+		out, _, err := u.visibleFollows(recs, viewerID, other)
+		return len(out), err
+	}
+	if followers, err = count(u.Follows.ListFollowers, func(rec *domain.FollowRecord) string { return rec.FollowerID }); err != nil {
+		return 0, 0, err
+	}
+	if following, err = count(u.Follows.ListFollowing, func(rec *domain.FollowRecord) string { return rec.FolloweeID }); err != nil {
+		return 0, 0, err
+	}
+	return followers, following, nil
+}
+
+// visibleFollows: 閲覧者から見えるユーザーのフォローだけを、相手のユーザーとともに返す
+// （そのためページが limit 件に満たないことがある）
+func (u *Usecase) visibleFollows(recs []*domain.FollowRecord, viewerID string, other func(rec *domain.FollowRecord) string) ([]*FollowEntry, []*domain.UserRecord, error) {
+	out := make([]*FollowEntry, 0, len(recs))
+	users := make([]*domain.UserRecord, 0, len(recs))
+	for _, rec := range recs {
+		otherID := other(rec)
+		otherRec, err := u.Repo.FindByID(u.Tenant, otherID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return nil, nil, err
+		}
+		if otherID != viewerID {
+			visible, err := u.profileVisible(otherRec, viewerID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !visible {
+				continue
+			}
+		}
+		out = append(out, &FollowEntry{ID: rec.ID, UserID: otherID, At: rec.CreatedAt})
+		users = append(users, otherRec)
+	}
+	return out, users, nil
+}
+
+// findVisible: 本人以外の viewerID から見える userID のユーザーを返す。見えなければ ErrNotFound
+func (u *Usecase) findVisible(userID, viewerID string) (*domain.UserRecord, error) {
+	rec, err := u.Repo.FindByID(u.Tenant, userID)
+	if err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	visible, err := u.profileVisible(rec, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrNotFound
+	}
+	return rec, nil
+}
+
+// profileVisible: viewerID（本人以外の認証済みユーザー）が target のプロフィールを見られるか（利用停止・ブロック・公開範囲）
+func (u *Usecase) profileVisible(target *domain.UserRecord, viewerID string) (bool, error) {
+	if !target.DeactivatedAt.IsZero() {
+		return false, nil
+	}
+	blocked, err := u.Blocks.Blocked(target.UserID, viewerID)
+	if err != nil || blocked {
+		return false, err
+	}
+	vis := target.Privacy.ProfileVisibility()
+	if vis != domain.VisibilityGroups {
+		return vis.VisibleTo(domain.Viewer{Authenticated: true}), nil
+	}
+	viewer, err := u.viewerOf(target.UserID, viewerID)
+	if err != nil {
+		return false, err
+	}
+	return vis.VisibleTo(viewer), nil
+}
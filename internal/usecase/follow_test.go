@@ -0,0 +1,57 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// ユーザー情報のフォロワー数・フォロー数は、一覧と同じく閲覧者から見えるユーザーだけを数える
+func TestFollowCountsMatchVisibleLists(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	users := map[string]string{"TaroYamada": "PaSSwd4TY", "HanakoSato": "PaSSwd4HS", "JiroSuzuki": "PaSSwd4JS", "SaburoIto": "PaSSwd4SI"}
+	for id, pw := range users {
+		mustSignUp(t, uc, id, pw)
+	}
+	for _, id := range []string{"HanakoSato", "JiroSuzuki", "SaburoIto"} {
+		if err := uc.Follow(id, basic(id, users[id]), "TaroYamada"); err != nil {
+			t.Fatalf("Follow(%s): %v", id, err)
+		}
+	}
+	for _, id := range []string{"HanakoSato", "JiroSuzuki"} {
+		if err := uc.Follow("TaroYamada", basic("TaroYamada", users["TaroYamada"]), id); err != nil {
+			t.Fatalf("Follow(%s): %v", id, err)
+		}
+	}
+
+	// 非公開にしたユーザーと利用停止したユーザーは一覧から除かれる
+	if _, err := uc.SetPrivacy("JiroSuzuki", basic("JiroSuzuki", users["JiroSuzuki"]), domain.VisibilityPrivate, nil); err != nil {
+		t.Fatalf("SetPrivacy: %v", err)
+	}
+	if _, err := uc.Deactivate("SaburoIto", basic("SaburoIto", users["SaburoIto"])); err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+
+	for _, viewerID := range []string{"TaroYamada", "HanakoSato"} {
+		cred := basic(viewerID, users[viewerID])
+		view, err := uc.GetUser("TaroYamada", cred)
+		if err != nil {
+			t.Fatalf("GetUser as %s: %v", viewerID, err)
+		}
+		followers, _, err := uc.ListFollowers("TaroYamada", cred, 0, 0, usecase.UserSort{})
+		if err != nil {
+			t.Fatalf("ListFollowers: %v", err)
+		}
+		following, _, err := uc.ListFollowing("TaroYamada", cred, 0, 0, usecase.UserSort{})
+		if err != nil {
+			t.Fatalf("ListFollowing: %v", err)
+		}
+		if view.Followers != 1 || view.Followers != len(followers) {
+			t.Errorf("as %s: followers count = %d, list = %d, want 1", viewerID, view.Followers, len(followers))
+		}
+		if view.Following != 1 || view.Following != len(following) {
+			t.Errorf("as %s: following count = %d, list = %d, want 1", viewerID, view.Following, len(following))
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// ErrGroupNotFound: グループが存在しない、または閲覧者がメンバーでない（ErrNotFound として扱える）
+var ErrGroupNotFound = fmt.Errorf("%w: group", ErrNotFound)
+
+// GroupView: グループと、閲覧者から見えるメンバー
+type GroupView struct {
+	*domain.GroupRecord
+	Members []*domain.GroupMembership
+	// Role: 閲覧者の役割
+	Role domain.GroupRole
+}
+
+// UserGroup: ユーザーが参加している（本人については招待中を含む）グループと役割
+type UserGroup struct {
+	*domain.GroupRecord
+	Role     domain.GroupRole
+	JoinedAt time.Time
+	Pending  bool
+}
+
+// CreateGroup: グループを作成する。作成したユーザーが最初の owner になる
+func (u *Usecase) CreateGroup(cred Credential, name string) (*GroupView, error) {
+	p, err := u.authenticateActive(cred)
+	if err != nil {
+		return nil, err
+	}
+	name, err = domain.NormalizeGroupName(name)
+	if err != nil {
+		return nil, mapValidationError(err)
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	g := &domain.GroupRecord{ID: id, Name: name, CreatedAt: now, UpdatedAt: now}
+	owner := &domain.GroupMembership{GroupID: id, UserID: p.user.UserID, Role: domain.GroupOwner, JoinedAt: now}
+	if err := u.Groups.Create(g, owner); err != nil {
+		return nil, err
+	}
+	return &GroupView{GroupRecord: g, Members: []*domain.GroupMembership{owner}, Role: domain.GroupOwner}, nil
+}
+
+// GetGroup: メンバーのみ閲覧できる
+func (u *Usecase) GetGroup(cred Credential, groupID string) (*GroupView, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, err
+	}
+	g, m, err := u.groupMember(p, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return u.groupView(g, m, p.user.UserID)
+}
+
+// RenameGroup: owner のみ
+func (u *Usecase) RenameGroup(cred Credential, groupID, name string) (*GroupView, error) {
+	p, g, m, err := u.groupOwner(cred, groupID)
+	if err != nil {
+		return nil, err
+	}
+	name, err = domain.NormalizeGroupName(name)
+	if err != nil {
+		return nil, mapValidationError(err)
+	}
+	now := u.now()
+	if err := u.Groups.Rename(g.ID, name, now); err != nil {
+		return nil, mapGroupNotFound(err)
+	}
+	g.Name, g.UpdatedAt = name, now
+	return u.groupView(g, m, p.user.UserID)
+}
+
+// DeleteGroup: owner のみ。メンバーシップもすべて削除する
+func (u *Usecase) DeleteGroup(cred Credential, groupID string) error {
+	_, g, _, err := u.groupOwner(cred, groupID)
+	if err != nil {
+		return err
+	}
+	return mapGroupNotFound(u.Groups.Delete(g.ID))
+}
+
+// SetGroupMember: owner が userID をメンバーに招待する、または役割を変える（role 空文字は member）。
+// 招待できるのは owner からプロフィールが見えるユーザーのみで、本人が AcceptGroupInvitation で承諾するまでは
+// メンバーとして扱わない（グループの閲覧や公開範囲 groups の対象にならない）。created は新たに招待した場合に true
+func (u *Usecase) SetGroupMember(cred Credential, groupID, userID string, role domain.GroupRole) (m *domain.GroupMembership, created bool, err error) {
+	p, g, _, err := u.groupOwner(cred, groupID)
+	if err != nil {
+		return nil, false, err
+	}
+	if role == "" {
+		role = domain.GroupMember
+	}
+	if !role.Valid() {
+		return nil, false, &ValidationError{Reason: ValidationReasonGroupRole}
+	}
+	m, err = u.Groups.Membership(g.ID, userID)
+	switch {
+	case err == nil:
+		if m.Role == role {
+			return m, false, nil
+		}
+		if m.Role == domain.GroupOwner && !m.Pending {
+			if err := u.requireOtherOwner(g.ID, userID); err != nil {
+				return nil, false, err
+			}
+		}
+		if err := u.Groups.SetRole(g.ID, userID, role); err != nil {
+			return nil, false, mapRepoNotFound(err)
+		}
+		m.Role = role
+		return m, false, nil
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, false, err
+	}
+	if _, err := u.findVisible(userID, p.user.UserID); err != nil {
+		return nil, false, err
+	}
+	m = &domain.GroupMembership{GroupID: g.ID, UserID: userID, Role: role, JoinedAt: u.now(), Pending: true}
+	if err := u.Groups.AddMember(m); err != nil {
+		return nil, false, err
+	}
+	return m, true, nil
+}
+
+// AcceptGroupInvitation: 本人が招待を承諾してメンバーになる。参加済みならそのまま返す
+func (u *Usecase) AcceptGroupInvitation(cred Credential, groupID, userID string) (*domain.GroupMembership, error) {
+	p, err := u.authenticateActive(cred)
+	if err != nil {
+		return nil, err
+	}
+	// 承諾すると公開範囲 groups の項目が他のメンバーに見えるようになるため、なりすまし中は不可
+	if p.impersonating() {
+		return nil, ErrImpersonationForbidden
+	}
+	if userID != p.user.UserID {
+		return nil, ErrNoPerm
+	}
+	m, err := u.Groups.Membership(groupID, userID)
+	if err != nil {
+		return nil, mapGroupNotFound(err)
+	}
+	if !m.Pending {
+		return m, nil
+	}
+	now := u.now()
+	if err := u.Groups.Accept(groupID, userID, now); err != nil {
+		return nil, mapGroupNotFound(err)
+	}
+	m.Pending, m.JoinedAt = false, now
+	return m, nil
+}
+
+// RemoveGroupMember: owner は誰でも（招待の取り消しを含む）、メンバーは自分自身（脱退・招待の辞退）のみ外せる。
+// 最後の owner は外せない
+func (u *Usecase) RemoveGroupMember(cred Credential, groupID, userID string) error {
+	p, err := u.authenticateActive(cred)
+	if err != nil {
+		return err
+	}
+	if p.impersonating() {
+		return ErrImpersonationForbidden
+	}
+	if userID != p.user.UserID {
+		_, self, err := u.groupMember(p, groupID)
+		if err != nil {
+			return err
+		}
+		if self.Role != domain.GroupOwner {
+			return ErrNoPerm
+		}
+	}
+	m, err := u.Groups.Membership(groupID, userID)
+	if err != nil {
+		if userID == p.user.UserID {
+			return mapGroupNotFound(err)
+		}
+		return mapRepoNotFound(err)
+	}
+	if m.Role == domain.GroupOwner && !m.Pending {
+		if err := u.requireOtherOwner(groupID, userID); err != nil {
+			return err
+		}
+	}
+	return mapRepoNotFound(u.Groups.RemoveMember(groupID, userID))
+}
+
+// ListUserGroups: 本人は参加しているすべてのグループ（招待中を含む）、他のユーザーについては互いに参加しているグループのみ
+func (u *Usecase) ListUserGroups(pathUserID string, cred Credential) ([]*UserGroup, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, err
+	}
+	viewerID := p.user.UserID
+	if pathUserID != viewerID {
+		if _, err := u.findVisible(pathUserID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+	list, err := u.userGroups(pathUserID)
+	if err != nil || pathUserID == viewerID {
+		return list, err
+	}
+	shared := list[:0]
+	for _, g := range list {
+		if g.Pending {
+			continue
+		}
+		ok, err := u.isGroupMember(g.ID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			shared = append(shared, g)
+		}
+	}
+	return shared, nil
+}
+
+// userGroups: userID が参加しているグループ（参加の古い順）
+func (u *Usecase) userGroups(userID string) ([]*UserGroup, error) {
+	memberships, err := u.Groups.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*UserGroup, 0, len(memberships))
+	for _, m := range memberships {
+		g, err := u.Groups.Find(m.GroupID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		out = append(out, &UserGroup{GroupRecord: g, Role: m.Role, JoinedAt: m.JoinedAt, Pending: m.Pending})
+	}
+	return out, nil
+}
+
+// groupMember: p が groupID のメンバー（招待中を除く）であること。メンバーでなければグループの存在を明かさない
+func (u *Usecase) groupMember(p *principal, groupID string) (*domain.GroupRecord, *domain.GroupMembership, error) {
+	m, err := u.Groups.Membership(groupID, p.user.UserID)
+	if err != nil {
+		return nil, nil, mapGroupNotFound(err)
+	}
+	if m.Pending {
+		return nil, nil, ErrGroupNotFound
+	}
+	g, err := u.Groups.Find(groupID)
+	if err != nil {
+		return nil, nil, mapGroupNotFound(err)
+	}
+	return g, m, nil
+}
+
+// groupOwner: groupID の owner のみ許可する変更の認証（メンバーのみなら ErrNoPerm）。
+// グループの変更は他のメンバーにも及ぶため、なりすまし中は不可
+func (u *Usecase) groupOwner(cred Credential, groupID string) (*principal, *domain.GroupRecord, *domain.GroupMembership, error) {
+	p, err := u.authenticateActive(cred)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if p.impersonating() {
+		return nil, nil, nil, ErrImpersonationForbidden
+	}
+	g, m, err := u.groupMember(p, groupID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if m.Role != domain.GroupOwner {
+		return nil, nil, nil, ErrNoPerm
+	}
+	return p, g, m, nil
+}
+
+// groupView: g のメンバーのうち viewerID から見えるもの（利用停止・ブロック・非公開のユーザーは除く）
+func (u *Usecase) groupView(g *domain.GroupRecord, m *domain.GroupMembership, viewerID string) (*GroupView, error) {
+	members, err := u.Groups.ListMembers(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	visible := members[:0]
+	for _, member := range members {
+		if member.UserID != viewerID {
+			rec, err := u.Repo.FindByID(u.Tenant, member.UserID)
+			if err != nil {
+				if errors.Is(err, domain.ErrNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			ok, err := u.profileVisible(rec, viewerID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		visible = append(visible, member)
+	}
+	return &GroupView{GroupRecord: g, Members: visible, Role: m.Role}, nil
+}
+
+// requireOtherOwner: userID のほかに（招待中でない）owner がいなければ ValidationReasonGroupOwnerRequired
+func (u *Usecase) requireOtherOwner(groupID, userID string) error {
+	members, err := u.Groups.ListMembers(groupID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.Role == domain.GroupOwner && !m.Pending && m.UserID != userID {
+			return nil
+		}
+	}
+	return &ValidationError{Reason: ValidationReasonGroupOwnerRequired}
+}
+
+// leaveGroups: 退会するユーザーをすべてのグループから外す（招待は辞退する）。最後の owner だった場合は
+// 最も古くから参加しているメンバーを owner にし、（招待中を除く）メンバーがいなくなるグループは削除する
+func (u *Usecase) leaveGroups(userID string) error {
+	memberships, err := u.Groups.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+	for _, m := range memberships {
+		if m.Pending {
+			if err := u.Groups.RemoveMember(m.GroupID, userID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return err
+			}
+			continue
+		}
+		members, err := u.Groups.ListMembers(m.GroupID)
+		if err != nil {
+			return err
+		}
+		var successor *domain.GroupMembership
+		ownerLeft := false
+		for _, other := range members {
+			if other.UserID == userID || other.Pending {
+				continue
+			}
+			if successor == nil {
+				successor = other
+			}
+			if other.Role == domain.GroupOwner {
+				ownerLeft = true
+			}
+		}
+		switch {
+		case successor == nil:
+			err = u.Groups.Delete(m.GroupID)
+		case m.Role == domain.GroupOwner && !ownerLeft:
+			if err = u.Groups.SetRole(m.GroupID, successor.UserID, domain.GroupOwner); err == nil {
+				err = u.Groups.RemoveMember(m.GroupID, userID)
+			}
+		default:
+			err = u.Groups.RemoveMember(m.GroupID, userID)
+		}
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewerOf: 本人以外の認証済みユーザー viewerID から targetID を見る場合の閲覧者。
+// 招待中のメンバーシップは、本人が承諾していないため同じグループとみなさない
+func (u *Usecase) viewerOf(targetID, viewerID string) (domain.Viewer, error) {
+	viewer := domain.Viewer{Authenticated: true}
+	memberships, err := u.Groups.ListByUser(targetID)
+	if err != nil {
+		return viewer, err
+	}
+	for _, m := range memberships {
+		if m.Pending {
+			continue
+		}
+		ok, err := u.isGroupMember(m.GroupID, viewerID)
+		if err != nil {
+			return viewer, err
+		}
+		if ok {
+			viewer.SharesGroup = true
+			break
+		}
+	}
+	return viewer, nil
+}
+
+// isGroupMember: userID が groupID のメンバー（招待中を除く）か
+func (u *Usecase) isGroupMember(groupID, userID string) (bool, error) {
+	m, err := u.Groups.Membership(groupID, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !m.Pending, nil
+}
+
+func mapGroupNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrGroupNotFound
+	}
+	return err
+}
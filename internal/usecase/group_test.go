@@ -0,0 +1,166 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+func TestGroupInvitationRequiresAcceptance(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	taro, hanako := basic("TaroYamada", "PaSSwd4TY"), basic("HanakoSato", "PaSSwd4HS")
+
+	g, err := uc.CreateGroup(taro, "Team")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	m, created, err := uc.SetGroupMember(taro, g.ID, "HanakoSato", "")
+	if err != nil || !created || !m.Pending {
+		t.Fatalf("SetGroupMember = %+v, %v, %v, want a pending invitation", m, created, err)
+	}
+	if _, err := uc.SetPrivacy("HanakoSato", hanako, domain.VisibilityGroups, nil); err != nil {
+		t.Fatalf("SetPrivacy: %v", err)
+	}
+
+	// 招待しただけでは同じグループのメンバーとして扱わない
+	if _, err := uc.GetUser("HanakoSato", taro); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("GetUser before acceptance: err = %v, want ErrNotFound", err)
+	}
+	if _, err := uc.GetGroup(hanako, g.ID); !errors.Is(err, usecase.ErrGroupNotFound) {
+		t.Errorf("GetGroup by invitee: err = %v, want ErrGroupNotFound", err)
+	}
+	list, err := uc.ListUserGroups("HanakoSato", hanako)
+	if err != nil || len(list) != 1 || !list[0].Pending {
+		t.Errorf("ListUserGroups(self) = %+v, %v, want the pending invitation", list, err)
+	}
+	if _, err := uc.AcceptGroupInvitation(taro, g.ID, "HanakoSato"); !errors.Is(err, usecase.ErrNoPerm) {
+		t.Errorf("AcceptGroupInvitation by owner: err = %v, want ErrNoPerm", err)
+	}
+
+	m, err = uc.AcceptGroupInvitation(hanako, g.ID, "HanakoSato")
+	if err != nil || m.Pending {
+		t.Fatalf("AcceptGroupInvitation = %+v, %v", m, err)
+	}
+	if _, err := uc.GetUser("HanakoSato", taro); err != nil {
+		t.Errorf("GetUser after acceptance: %v", err)
+	}
+	if _, err := uc.GetGroup(hanako, g.ID); err != nil {
+		t.Errorf("GetGroup after acceptance: %v", err)
+	}
+}
+
+func TestGroupInvitationDecline(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	taro, hanako := basic("TaroYamada", "PaSSwd4TY"), basic("HanakoSato", "PaSSwd4HS")
+	g, err := uc.CreateGroup(taro, "Team")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if _, _, err := uc.SetGroupMember(taro, g.ID, "HanakoSato", domain.GroupOwner); err != nil {
+		t.Fatalf("SetGroupMember: %v", err)
+	}
+	// 招待中の owner は owner の数に含めない
+	if err := uc.RemoveGroupMember(taro, g.ID, "TaroYamada"); !isValidation(err, usecase.ValidationReasonGroupOwnerRequired) {
+		t.Errorf("owner leaving with only a pending owner: err = %v, want group_owner_required", err)
+	}
+	if err := uc.RemoveGroupMember(hanako, g.ID, "HanakoSato"); err != nil {
+		t.Fatalf("decline: %v", err)
+	}
+	if _, err := uc.AcceptGroupInvitation(hanako, g.ID, "HanakoSato"); !errors.Is(err, usecase.ErrGroupNotFound) {
+		t.Errorf("accept after decline: err = %v, want ErrGroupNotFound", err)
+	}
+}
+
+func TestSetGroupMemberAuthenticatesBeforeValidatingRole(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	g, err := uc.CreateGroup(basic("TaroYamada", "PaSSwd4TY"), "Team")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if _, _, err := uc.SetGroupMember(usecase.Credential{}, g.ID, "HanakoSato", "admin"); !errors.Is(err, usecase.ErrAuthFailed) {
+		t.Errorf("anonymous: err = %v, want ErrAuthFailed", err)
+	}
+	if _, _, err := uc.SetGroupMember(basic("TaroYamada", "PaSSwd4TY"), g.ID, "HanakoSato", "admin"); !isValidation(err, usecase.ValidationReasonGroupRole) {
+		t.Errorf("owner: err = %v, want group_role", err)
+	}
+}
+
+func TestGroupChangesForbiddenWhileImpersonating(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.Admins = []string{"RootAdmin1"}
+	hash, err := bcrypt.GenerateFromPassword([]byte("PaSSwd4RA"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.ProvisionAdmin("RootAdmin1", string(hash)); err != nil {
+		t.Fatalf("ProvisionAdmin: %v", err)
+	}
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	mustSignUp(t, uc, "JiroSuzuki", "PaSSwd4JS")
+	taro, hanako := basic("TaroYamada", "PaSSwd4TY"), basic("HanakoSato", "PaSSwd4HS")
+
+	g, err := uc.CreateGroup(taro, "Team")
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if _, _, err := uc.SetGroupMember(taro, g.ID, "HanakoSato", ""); err != nil {
+		t.Fatalf("SetGroupMember: %v", err)
+	}
+	if _, err := uc.AcceptGroupInvitation(hanako, g.ID, "HanakoSato"); err != nil {
+		t.Fatalf("AcceptGroupInvitation: %v", err)
+	}
+	if _, _, err := uc.SetGroupMember(taro, g.ID, "JiroSuzuki", ""); err != nil {
+		t.Fatalf("SetGroupMember: %v", err)
+	}
+
+	impersonate := func(userID string) usecase.Credential {
+		t.Helper()
+		s, err := uc.Impersonate(basic("RootAdmin1", "PaSSwd4RA"), userID, "support")
+		if err != nil {
+			t.Fatalf("Impersonate(%s): %v", userID, err)
+		}
+		return usecase.Credential{Token: s.Token}
+	}
+	asTaro, asJiro := impersonate("TaroYamada"), impersonate("JiroSuzuki")
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"RenameGroup", func() error { _, err := uc.RenameGroup(asTaro, g.ID, "Renamed"); return err }},
+		{"DeleteGroup", func() error { return uc.DeleteGroup(asTaro, g.ID) }},
+		{"SetGroupMember", func() error {
+			_, _, err := uc.SetGroupMember(asTaro, g.ID, "HanakoSato", domain.GroupOwner)
+			return err
+		}},
+		{"RemoveGroupMember", func() error { return uc.RemoveGroupMember(asTaro, g.ID, "HanakoSato") }},
+		{"AcceptGroupInvitation", func() error { _, err := uc.AcceptGroupInvitation(asJiro, g.ID, "JiroSuzuki"); return err }},
+		{"DeclineGroupInvitation", func() error { return uc.RemoveGroupMember(asJiro, g.ID, "JiroSuzuki") }},
+	}
+	for _, tt := range tests {
+		if err := tt.call(); !errors.Is(err, usecase.ErrImpersonationForbidden) {
+			t.Errorf("%s: err = %v, want ErrImpersonationForbidden", tt.name, err)
+		}
+	}
+	got, err := uc.GetGroup(taro, g.ID)
+	if err != nil {
+		t.Fatalf("GetGroup: %v", err)
+	}
+	if got.Name != "Team" || len(got.Members) != 3 {
+		t.Errorf("group changed while impersonating: %+v", got)
+	}
+	m, err := uc.Groups.Membership(g.ID, "JiroSuzuki")
+	if err != nil || !m.Pending {
+		t.Errorf("invitation accepted while impersonating: %+v, %v", m, err)
+	}
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import "accountapi/internal/domain"
+
+const (
+	DefaultHistoryPageSize = 20
+	MaxHistoryPageSize     = 100
+)
+
+// ListProfileHistory: 本人のプロフィールの変更履歴（新しい順）。次ページがあれば次の beforeRev を返す
+func (u *Usecase) ListProfileHistory(pathUserID string, cred Credential, beforeRev uint64, limit int) ([]*domain.ProfileRevision, uint64, error) {
+	p, err := u.authenticateOwner(pathUserID, cred)
+	if err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = DefaultHistoryPageSize
+	}
+	if limit > MaxHistoryPageSize {
+		limit = MaxHistoryPageSize
+	}
+	// 続きの有無を判定するため 1 件多く取得する
+	revs, err := u.Revisions.List(p.user.UserID, beforeRev, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	var next uint64
+	if len(revs) > limit {
+		revs = revs[:limit]
+		next = revs[limit-1].Rev
+	}
+	return revs, next, nil
+}
+
+// RevertProfile: プロフィールを版 rev の変更後の値に戻す。通常の更新と同じ審査・検証を経て、新しい版として記録する
+func (u *Usecase) RevertProfile(pathUserID string, cred Credential, rev uint64) (*domain.User, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	target, err := u.Revisions.Find(p.user.UserID, rev)
+	if err != nil {
+		return nil, mapRepoNotFound(err)
+	}
+	return u.updateProfile(p, target.New.UpdateTo(p.user.Snapshot()), rev, cred.Client)
+}
@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/infrastructure/moderation"
+	"accountapi/internal/usecase"
+)
+
+func mustSchema(t *testing.T, def string) *domain.ProfileSchema {
+	t.Helper()
+	schema, err := domain.ParseProfileSchema([]byte(def))
+	if err != nil {
+		t.Fatalf("ParseProfileSchema: %v", err)
+	}
+	return schema
+}
+
+func mustUpdate(t *testing.T, uc *usecase.Usecase, userID string, cred usecase.Credential, upd domain.ProfileUpdate) {
+	t.Helper()
+	if _, err := uc.UpdateUser(userID, cred, upd, false); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+}
+
+func latestRevision(t *testing.T, uc *usecase.Usecase, userID string, cred usecase.Credential) *domain.ProfileRevision {
+	t.Helper()
+	revs, _, err := uc.ListProfileHistory(userID, cred, 0, 1)
+	if err != nil || len(revs) == 0 {
+		t.Fatalf("ListProfileHistory: %v (%d revisions)", err, len(revs))
+	}
+	return revs[0]
+}
+
+func TestRevertProfileRemovesLaterAttributes(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.ProfileSchema = mustSchema(t, `{"fields": [{"name": "website", "type": "url"}, {"name": "city"}]}`)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+
+	website, city := "https://example.com", "Tokyo"
+	mustUpdate(t, uc, "TaroYamada", cred, domain.ProfileUpdate{Attributes: map[string]*string{"website": &website}})
+	rev := latestRevision(t, uc, "TaroYamada", cred).Rev
+	mustUpdate(t, uc, "TaroYamada", cred, domain.ProfileUpdate{Attributes: map[string]*string{"city": &city}})
+
+	u, err := uc.RevertProfile("TaroYamada", cred, rev)
+	if err != nil {
+		t.Fatalf("RevertProfile: %v", err)
+	}
+	// 版 rev の後に追加した項目は削除され、rev の時点の項目は残る
+	if _, ok := u.Attributes["city"]; ok || u.Attributes["website"] != website {
+		t.Errorf("attributes = %v, want only website", u.Attributes)
+	}
+	latest := latestRevision(t, uc, "TaroYamada", cred)
+	if latest.RevertOf != rev || latest.ActorID != "TaroYamada" {
+		t.Errorf("revision = %+v, want RevertOf %d by the owner", latest, rev)
+	}
+	if _, ok := latest.Old.Attributes["city"]; !ok {
+		t.Errorf("old snapshot = %+v, want the value before the revert", latest.Old)
+	}
+}
+
+func TestRevertProfileIsModerated(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+
+	old, current := "ばか", "Taro"
+	mustUpdate(t, uc, "TaroYamada", cred, domain.ProfileUpdate{Nickname: &old})
+	rev := latestRevision(t, uc, "TaroYamada", cred).Rev
+	mustUpdate(t, uc, "TaroYamada", cred, domain.ProfileUpdate{Nickname: &current})
+
+	// 記録した時点では通った値も、戻すときの規則で審査する
+	uc.Moderator = moderation.NewWordList([]moderation.Rule{{Word: "ばか", Action: domain.ModerationReject}})
+	if _, err := uc.RevertProfile("TaroYamada", cred, rev); !isValidation(err, usecase.ValidationReasonContentRejected) {
+		t.Errorf("reject rule: err = %v, want content_rejected", err)
+	}
+	uc.Moderator = moderation.NewWordList([]moderation.Rule{{Word: "ばか", Action: domain.ModerationHold}})
+	if _, err := uc.RevertProfile("TaroYamada", cred, rev); !errors.Is(err, usecase.ErrPendingReview) {
+		t.Errorf("hold rule: err = %v, want ErrPendingReview", err)
+	}
+	if latest := latestRevision(t, uc, "TaroYamada", cred); latest.RevertOf != 0 || latest.New.Nickname != current {
+		t.Errorf("revision = %+v, want no revert recorded", latest)
+	}
+}
+
+func TestRevertProfileValidatesOldValues(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.ProfileSchema = mustSchema(t, `{"fields": [{"name": "website", "type": "url"}]}`)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+
+	old, current := "https://example.com/taro-yamada", "https://example.com"
+	mustUpdate(t, uc, "TaroYamada", cred, domain.ProfileUpdate{Attributes: map[string]*string{"website": &old}})
+	rev := latestRevision(t, uc, "TaroYamada", cred).Rev
+	mustUpdate(t, uc, "TaroYamada", cred, domain.ProfileUpdate{Attributes: map[string]*string{"website": &current}})
+
+	// スキーマが厳しくなった後は、以前の値に戻せない
+	uc.ProfileSchema = mustSchema(t, `{"fields": [{"name": "website", "type": "url", "max_length": 20}]}`)
+	if _, err := uc.RevertProfile("TaroYamada", cred, rev); !isValidation(err, usecase.ValidationReasonAttributeInvalid) {
+		t.Fatalf("err = %v, want profile_attribute_invalid", err)
+	}
+	if latest := latestRevision(t, uc, "TaroYamada", cred); latest.RevertOf != 0 || latest.New.Attributes["website"] != current {
+		t.Errorf("revision = %+v, want no revert recorded", latest)
+	}
+
+	if _, err := uc.RevertProfile("TaroYamada", cred, 999); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("unknown revision: err = %v, want ErrNotFound", err)
+	}
+}
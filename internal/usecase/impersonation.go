@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"accountapi/internal/domain"
+)
+
+// DefaultImpersonationTTL: ImpersonationTTL 未指定時のなりすましセッションの有効期間
+const DefaultImpersonationTTL = 15 * time.Minute
+
+// Impersonate: 管理者が対象ユーザーとして振る舞うための短時間のセッションを発行する。
+// 理由は必須で、開始は対象ユーザーの利用履歴に記録される
+func (u *Usecase) Impersonate(cred Credential, targetUserID, reason string) (*Session, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, err
+	}
+	// なりすまし中のさらなるなりすましは不可
+	if p.impersonating() || !u.isAdmin(p.user.UserID) {
+		return nil, ErrNoPerm
+	}
+	if reason == "" || utf8.RuneCountInString(reason) > 200 || !utf8.ValidString(reason) {
+		return nil, &ValidationError{Reason: ValidationReasonImpersonationReason}
+	}
+	// 管理者どうしのなりすましは権限昇格につながるため不可
+	if targetUserID == p.user.UserID || u.isAdmin(targetUserID) {
+		return nil, ErrNoPerm
+	}
+	target, err := u.Repo.FindByID(u.Tenant, targetUserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	token, err := newToken(32)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	if err := u.pruneSessions(target.UserID, now); err != nil {
+		return nil, err
+	}
+	rec := &domain.SessionRecord{
+		ID:             id,
+		UserID:         target.UserID,
+		TokenHash:      hashToken(token),
+		CreatedAt:      now,
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(u.impersonationTTL()),
+		IP:             cred.Client.IP,
+		UserAgent:      cred.Client.UserAgent,
+		ImpersonatorID: p.user.UserID,
+	}
+	if err := u.Sessions.Create(rec); err != nil {
+		return nil, err
+	}
+	u.appendActivity(&domain.ActivityRecord{
+		UserID:  target.UserID,
+		Kind:    domain.ActivityImpersonationStart,
+		Outcome: domain.ActivitySuccess,
+		ActorID: p.user.UserID,
+		Detail:  reason,
+	}, cred.Client)
+	s := toSession(rec, "")
+	s.Token = token
+	return s, nil
+}
+
+func (u *Usecase) isAdmin(userID string) bool {
+	return slices.Contains(u.Admins, userID)
+}
+
+func (u *Usecase) impersonationTTL() time.Duration {
+	if u.ImpersonationTTL > 0 {
+		return u.ImpersonationTTL
+	}
+	return DefaultImpersonationTTL
+}
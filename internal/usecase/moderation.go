@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"errors"
+
+	"accountapi/internal/domain"
+)
+
+// Moderator: nickname・comment の内容を審査する。field は "nickname" または "comment"
+type Moderator interface {
+	Moderate(field, text string) domain.ModerationVerdict
+}
+
+// ErrPendingReview: 変更は審査待ちとして受け付けられ、まだ反映されていない
+var ErrPendingReview = errors.New("pending review")
+
+// DefaultModerationListLimit: 審査待ち一覧の既定の件数
+const DefaultModerationListLimit = 50
+
+// moderate: Moderator の判定を適用した更新内容を返す（nickname は正規化した値を返す）。
+// reject は ValidationError、hold は held=true（該当語は matches）
+func (u *Usecase) moderate(upd domain.ProfileUpdate) (out domain.ProfileUpdate, held bool, matches []string, err error) {
+	out = upd
+	if u.Moderator == nil {
+		return out, false, nil, nil
+	}
+	action := domain.ModerationAllow
+	check := func(field string, text *string) *string {
+		if text == nil || *text == "" {
+			return text
+		}
+		v := u.Moderator.Moderate(field, *text)
+		matches = append(matches, v.Matches...)
+		if v.Action.Severity() > action.Severity() {
+			action = v.Action
+		}
+		if v.Action == domain.ModerationMask {
+			return &v.Text
+		}
+		return text
+	}
+	if upd.Nickname != nil {
+		// 保存されるのは正規化後の値のため、正規化してから審査する
+		nn := domain.NormalizeNickname(*upd.Nickname)
+		out.Nickname = check("nickname", &nn)
+	}
+	out.Comment = check("comment", upd.Comment)
+	switch action {
+	case domain.ModerationReject:
+		return upd, false, matches, &ValidationError{Reason: ValidationReasonContentRejected}
+	case domain.ModerationHold:
+		// 審査では元の文字列を確認する
+		return upd, true, matches, nil
+	}
+	return out, false, matches, nil
+}
+
+// holdProfileUpdate: 検証済みの更新を審査待ちに入れる。nickname の制約は先に確認しておく（反映は承認時）
+func (u *Usecase) holdProfileUpdate(d *domain.User, upd domain.ProfileUpdate, matches []string) error {
+	if upd.Nickname != nil && u.nicknameReserved(d.Nickname) {
+		return &ValidationError{Reason: ValidationReasonNicknameReserved}
+	}
+	if upd.Nickname != nil && d.Nickname != "" {
+		if err := u.Repo.CheckNickname(u.Tenant, d.UserID, d.Nickname, u.nicknamePolicy()); err != nil {
+			if reason, ok := nicknameConflictReason(err); ok {
+				return &ValidationError{Reason: reason}
+			}
+			return err
+		}
+	}
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	return u.Moderation.Put(&domain.ModerationRecord{
+		ID:        id,
+		UserID:    d.UserID,
+		Update:    upd,
+		Matches:   matches,
+		CreatedAt: u.now(),
+	})
+}
+
+// ListModeration: 審査待ちのプロフィール変更（管理者のみ、古い順）
+func (u *Usecase) ListModeration(cred Credential, limit int) ([]*domain.ModerationRecord, error) {
+	if _, err := u.authenticateAdmin(cred); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > DefaultModerationListLimit {
+		limit = DefaultModerationListLimit
+	}
+	return u.Moderation.List(limit)
+}
+
+// DecideModeration: 審査待ちの変更を承認して反映する、または却下して破棄する（管理者のみ）。
+// 承認時は現在のプロフィール・設定で改めて検証する
+func (u *Usecase) DecideModeration(cred Credential, id string, approve bool) (*domain.User, error) {
+	admin, err := u.authenticateAdmin(cred)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := u.Moderation.Find(id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	target, err := u.Repo.FindByID(u.Tenant, rec.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			_ = u.Moderation.Delete(id)
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	d := toDomain(target)
+	rev := &domain.ProfileRevision{UserID: d.UserID, ActorID: admin.user.UserID, Old: d.Snapshot()}
+	activity := &domain.ActivityRecord{UserID: d.UserID, Kind: domain.ActivityProfileUpdate, ActorID: admin.user.UserID}
+	if !approve {
+		if err := u.Moderation.Delete(id); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+		activity.Outcome = domain.ActivityFailure
+		activity.Detail = "rejected in moderation"
+		u.appendActivity(activity, cred.Client)
+		return d, nil
+	}
+	if err := d.ApplyProfileUpdate(rec.Update, u.ProfileSchema); err != nil {
+		return nil, mapValidationError(err)
+	}
+	if err := u.saveProfile(d, rec.Update, rev); err != nil {
+		return nil, err
+	}
+	if err := u.Moderation.Delete(id); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	activity.Outcome = domain.ActivitySuccess
+	activity.Detail = "approved in moderation"
+	u.appendActivity(activity, cred.Client)
+	return d, nil
+}
+
+// authenticateAdmin: 管理者本人（なりすまし中でない）であることを確認する
+func (u *Usecase) authenticateAdmin(cred Credential) (*principal, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, err
+	}
+	if p.impersonating() || !u.isAdmin(p.user.UserID) {
+		return nil, ErrNoPerm
+	}
+	return p, nil
+}
@@ -0,0 +1,27 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/infrastructure/moderation"
+	"accountapi/internal/usecase"
+)
+
+func TestUpdateUserModeratesNormalizedNickname(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.Moderator = moderation.NewWordList([]moderation.Rule{{Word: "ばか", Action: domain.ModerationReject}})
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+
+	for _, nickname := range []string{
+		"\u306f\u3099\u304b",       // NFD（保存時に NFC で「ばか」になる）
+		"\uff8a\uff9e\uff76",       // 半角カタカナ
+		"\u30cf\u3099\u30ab\u30fc", // NFD のカタカナ
+	} {
+		_, err := uc.UpdateUser("TaroYamada", cred, domain.ProfileUpdate{Nickname: &nickname}, false)
+		if !isValidation(err, usecase.ValidationReasonContentRejected) {
+			t.Errorf("nickname %q: err = %v, want content_rejected", nickname, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"errors"
+
+	"accountapi/internal/domain"
+)
+
+// NicknameAvailability: nickname の使用可否
+type NicknameAvailability struct {
+	Nickname  string // 正規化後の値
+	Available bool
+	// Reason: 使えない理由（nickname_taken・nickname_confusable・nickname_reserved・profile_constraint）
+	Reason ValidationReason
+}
+
+// CheckNicknameAvailability: 認証ユーザーが nickname を使えるかを返す（本人が使用中のものは使用可）
+func (u *Usecase) CheckNicknameAvailability(cred Credential, nickname string) (*NicknameAvailability, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, err
+	}
+	nn, err := domain.ValidateNickname(nickname)
+	if err != nil || nn == "" {
+		return &NicknameAvailability{Nickname: nn, Reason: ValidationReasonProfileConstraint}, nil
+	}
+	if u.nicknameReserved(nn) {
+		return &NicknameAvailability{Nickname: nn, Reason: ValidationReasonNicknameReserved}, nil
+	}
+	if err := u.Repo.CheckNickname(u.Tenant, p.user.UserID, nn, u.nicknamePolicy()); err != nil {
+		if reason, ok := nicknameConflictReason(err); ok {
+			return &NicknameAvailability{Nickname: nn, Reason: reason}, nil
+		}
+		return nil, err
+	}
+	return &NicknameAvailability{Nickname: nn, Available: true}, nil
+}
+
+func (u *Usecase) nicknamePolicy() domain.NicknamePolicy {
+	return domain.NicknamePolicy{Unique: u.UniqueNicknames, RejectConfusable: u.RejectConfusableNicknames}
+}
+
+// nicknameReserved: 予約済みの名前と同一視されるか
+func (u *Usecase) nicknameReserved(nickname string) bool {
+	if nickname == "" {
+		return false
+	}
+	key := domain.NicknameSkeleton(nickname)
+	for _, reserved := range u.ReservedNicknames {
+		if domain.NicknameSkeleton(reserved) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func nicknameConflictReason(err error) (ValidationReason, bool) {
+	switch {
+	case errors.Is(err, domain.ErrNicknameTaken):
+		return ValidationReasonNicknameTaken, true
+	case errors.Is(err, domain.ErrNicknameConfusable):
+		return ValidationReasonNicknameConfusable, true
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+func TestNicknameAvailability(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.UniqueNicknames = true
+	uc.RejectConfusableNicknames = true
+	uc.ReservedNicknames = []string{"admin"}
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	taro, hanako := basic("TaroYamada", "PaSSwd4TY"), basic("HanakoSato", "PaSSwd4HS")
+	nickname := "Taro"
+	if _, err := uc.UpdateUser("TaroYamada", taro, domain.ProfileUpdate{Nickname: &nickname}, false); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cred     usecase.Credential
+		nickname string
+		want     usecase.ValidationReason // 空なら使用可
+	}{
+		{"own nickname", taro, "Taro", ""},
+		{"own nickname in another case", taro, "TARO", ""},
+		{"taken", hanako, "Taro", usecase.ValidationReasonNicknameTaken},
+		{"taken, case only", hanako, "taro", usecase.ValidationReasonNicknameTaken},
+		{"confusable with user_id", hanako, "TaroYamada", usecase.ValidationReasonNicknameConfusable},
+		{"reserved", hanako, "admin", usecase.ValidationReasonNicknameReserved},
+		{"reserved lookalike", taro, "ADM1N", usecase.ValidationReasonNicknameReserved},
+		{"free", hanako, "Hanako", ""},
+	}
+	for _, tt := range tests {
+		got, err := uc.CheckNicknameAvailability(tt.cred, tt.nickname)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.Available != (tt.want == "") || got.Reason != tt.want {
+			t.Errorf("%s: available = %v, reason = %q, want reason %q", tt.name, got.Available, got.Reason, tt.want)
+		}
+	}
+}
+
+func TestUpdateUserRejectsReservedNickname(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	uc.ReservedNicknames = []string{"admin"}
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	cred := basic("TaroYamada", "PaSSwd4TY")
+
+	for _, nickname := range []string{"admin", "Admin", "аdmin"} { // 最後は先頭がキリル文字
+		_, err := uc.UpdateUser("TaroYamada", cred, domain.ProfileUpdate{Nickname: &nickname}, false)
+		if !isValidation(err, usecase.ValidationReasonNicknameReserved) {
+			t.Errorf("nickname %q: err = %v, want nickname_reserved", nickname, err)
+		}
+	}
+}
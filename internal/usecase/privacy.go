@@ -0,0 +1,29 @@
+package usecase
+
+import "accountapi/internal/domain"
+
+// GetPrivacy: 本人のプロフィールの公開範囲
+func (u *Usecase) GetPrivacy(pathUserID string, cred Credential) (domain.PrivacySettings, error) {
+	p, err := u.authenticateOwner(pathUserID, cred)
+	if err != nil {
+		return domain.PrivacySettings{}, err
+	}
+	return p.user.Privacy, nil
+}
+
+// SetPrivacy: 本人のプロフィールの公開範囲を置き換える
+func (u *Usecase) SetPrivacy(pathUserID string, cred Credential, profile domain.Visibility, fields map[string]domain.Visibility) (domain.PrivacySettings, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return domain.PrivacySettings{}, err
+	}
+	settings, err := domain.NewPrivacySettings(profile, fields, u.ProfileSchema)
+	if err != nil {
+		return domain.PrivacySettings{}, mapValidationError(err)
+	}
+	if err := u.Repo.UpdatePrivacy(u.Tenant, p.user.UserID, settings, u.now()); err != nil {
+		return domain.PrivacySettings{}, mapRepoNotFound(err)
+	}
+	u.recordFor(p, domain.ActivityPrivacyChange, domain.ActivitySuccess, cred.Client)
+	return settings, nil
+}
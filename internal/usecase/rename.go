@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// DefaultUserIDAliasTTL: UserIDAliasTTL 未指定時に旧 user_id を別名として残す期間
+const DefaultUserIDAliasTTL = 30 * 24 * time.Hour
+
+// MovedError: 旧 user_id が指定された。UserID は変更後の user_id
+type MovedError struct {
+	UserID string
+}
+
+func (e *MovedError) Error() string { return "moved to " + e.UserID }
+
+// UserRenamed: user_id の変更イベント
+type UserRenamed struct {
+	Tenant string
+	OldID  string
+	NewID  string
+	At     time.Time
+}
+
+// RenameListener: user_id の変更を受け取る。user_id を保存している外部のコンポーネントが参照を付け替えるためのもの。
+// 変更の完了後に同期的に呼ばれるため、時間のかかる処理は呼び出し側で非同期にする
+type RenameListener interface {
+	UserRenamed(ev UserRenamed)
+}
+
+// RenameUser: 本人の user_id を newID に変更する。旧 user_id は UserIDAliasTTL の間、別名として残り再登録できない。
+// セッション・利用履歴・関係などは新しい user_id に移し、変更は利用履歴に記録して RenameListeners に通知する
+func (u *Usecase) RenameUser(pathUserID string, cred Credential, newID string) (*domain.User, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
+	if err != nil {
+		return nil, err
+	}
+	// ログイン名の変更はアカウントの乗っ取りにつながるため、なりすまし中は不可
+	if p.impersonating() {
+		return nil, ErrImpersonationForbidden
+	}
+	// 管理者は設定で user_id を指定しているため変更できない
+	if u.isAdmin(p.user.UserID) {
+		return nil, ErrNoPerm
+	}
+	if err := domain.ValidateUserID(newID); err != nil {
+		return nil, mapValidationError(err)
+	}
+	// 管理者の user_id は未登録・退会済みでも取得できない（取得すると管理者の権限を得てしまう）
+	if u.isAdmin(newID) {
+		return nil, &ValidationError{Reason: ValidationReasonUserAlreadyExists}
+	}
+	d := p.user
+	oldID := d.UserID
+	if newID == oldID {
+		return d, nil
+	}
+	now := u.now()
+	if err := u.Repo.Rename(u.Tenant, oldID, newID, now.Add(u.userIDAliasTTL()), now); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, &ValidationError{Reason: ValidationReasonUserAlreadyExists}
+		}
+		return nil, mapRepoNotFound(err)
+	}
+	if err := u.renameRelated(oldID, newID, d.AvatarID); err != nil {
+		// 関係するレコードは renameRelated が戻している。ユーザーのレコードも戻し、newID の別名は残さない
+		if rbErr := u.Repo.Rename(u.Tenant, newID, oldID, now, now); rbErr != nil {
+			log.Printf("rename %s -> %s: rollback failed: %v", oldID, newID, rbErr)
+		}
+		return nil, err
+	}
+	d.UserID = newID
+	d.UpdatedAt = now
+	u.appendActivity(&domain.ActivityRecord{
+		UserID:  newID,
+		Kind:    domain.ActivityUserRename,
+		Outcome: domain.ActivitySuccess,
+		Detail:  oldID,
+	}, cred.Client)
+	ev := UserRenamed{Tenant: u.Tenant, OldID: oldID, NewID: newID, At: now}
+	for _, l := range u.RenameListeners {
+		l.UserRenamed(ev)
+	}
+	return d, nil
+}
+
+// userRenamer: user_id を含むレコードを持つ Repository
+type userRenamer interface {
+	RenameUser(oldID, newID string) error
+}
+
+// renameRelated: ユーザーのレコード以外に保存している oldID のものを newID に移す。
+// 途中で失敗した場合は移し終えたものを oldID に戻してからエラーを返す
+func (u *Usecase) renameRelated(oldID, newID, avatarID string) error {
+	var done []userRenamer
+	rollback := func() {
+		for _, repo := range slices.Backward(done) {
+			if err := repo.RenameUser(newID, oldID); err != nil {
+				log.Printf("rename %s -> %s: rollback failed: %v", oldID, newID, err)
+			}
+		}
+	}
+	for _, repo := range []userRenamer{u.Sessions, u.Activity, u.Moderation, u.Blocks, u.Follows, u.Revisions, u.Exports, u.Groups} {
+		if err := repo.RenameUser(oldID, newID); err != nil {
+			rollback()
+			return err
+		}
+		done = append(done, repo)
+	}
+	if avatarID == "" {
+		return nil
+	}
+	// BlobStore には移動がないため、アバターは複製してから旧 user_id の分を消す
+	if err := u.copyAvatar(oldID, newID, avatarID); err != nil {
+		if delErr := u.Blobs.DeletePrefix(avatarPrefix(newID, "")); delErr != nil {
+			log.Printf("rename %s -> %s: removing copied avatar failed: %v", oldID, newID, delErr)
+		}
+		rollback()
+		return err
+	}
+	// 複製は済んでいるため、旧 user_id の分を消せなくても変更は完了とする
+	if err := u.Blobs.DeletePrefix(avatarPrefix(oldID, "")); err != nil {
+		log.Printf("rename %s -> %s: removing old avatar failed: %v", oldID, newID, err)
+	}
+	return nil
+}
+
+func (u *Usecase) copyAvatar(oldID, newID, avatarID string) error {
+	for _, size := range append([]int{0}, domain.AvatarThumbnailSizes...) {
+		b, err := u.Blobs.Get(avatarKey(oldID, avatarID, size))
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if err := u.Blobs.Put(avatarKey(newID, avatarID, size), b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveAlias: userID が有効な旧 user_id なら、変更後のユーザーが viewerID から見える場合に限り MovedError を返す
+func (u *Usecase) resolveAlias(userID, viewerID string) error {
+	newID, err := u.Repo.ResolveAlias(u.Tenant, userID, u.now())
+	if err != nil {
+		return mapRepoNotFound(err)
+	}
+	if newID != viewerID {
+		if _, err := u.findVisible(newID, viewerID); err != nil {
+			return err
+		}
+	}
+	return &MovedError{UserID: newID}
+}
+
+func (u *Usecase) userIDAliasTTL() time.Duration {
+	if u.UserIDAliasTTL > 0 {
+		return u.UserIDAliasTTL
+	}
+	return DefaultUserIDAliasTTL
+}
@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"accountapi/internal/domain"
+	"accountapi/internal/usecase"
+)
+
+// failingGroupRepo: user_id の付け替えだけが失敗する GroupRepository
+type failingGroupRepo struct {
+	domain.GroupRepository
+}
+
+func (failingGroupRepo) RenameUser(oldID, newID string) error {
+	return errors.New("group store unavailable")
+}
+
+func TestRenameUserRollsBackOnFailure(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	if err := uc.Follow("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "HanakoSato"); err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	uc.Groups = failingGroupRepo{uc.Groups}
+
+	if _, err := uc.RenameUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "TaroYamada2"); err == nil {
+		t.Fatal("RenameUser: want error")
+	}
+
+	// ユーザーのレコードは元の user_id のまま、新しい user_id は別名としても残らない
+	if _, err := uc.Repo.FindByID(domain.DefaultTenant, "TaroYamada"); err != nil {
+		t.Errorf("FindByID(old): %v", err)
+	}
+	if _, err := uc.Repo.FindByID(domain.DefaultTenant, "TaroYamada2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("FindByID(new): err = %v, want ErrNotFound", err)
+	}
+	// 移し終えていた関係も元の user_id に戻っている
+	recs, err := uc.Follows.ListFollowers("HanakoSato", 0, 10)
+	if err != nil {
+		t.Fatalf("ListFollowers: %v", err)
+	}
+	if len(recs) != 1 || recs[0].FollowerID != "TaroYamada" {
+		t.Errorf("followers = %+v, want TaroYamada", recs)
+	}
+	if _, err := uc.SignUp("TaroYamada2", "PaSSwd4T2"); err != nil {
+		t.Errorf("SignUp(new id) after rollback: %v", err)
+	}
+}
+
+func TestRenameUserMovesRelatedRecords(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	mustSignUp(t, uc, "HanakoSato", "PaSSwd4HS")
+	if err := uc.Follow("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "HanakoSato"); err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+
+	if _, err := uc.RenameUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "TaroYamada2"); err != nil {
+		t.Fatalf("RenameUser: %v", err)
+	}
+	recs, err := uc.Follows.ListFollowers("HanakoSato", 0, 10)
+	if err != nil {
+		t.Fatalf("ListFollowers: %v", err)
+	}
+	if len(recs) != 1 || recs[0].FollowerID != "TaroYamada2" {
+		t.Errorf("followers = %+v, want TaroYamada2", recs)
+	}
+	if id, err := uc.Repo.ResolveAlias(domain.DefaultTenant, "TaroYamada", clock.Now()); err != nil || id != "TaroYamada2" {
+		t.Errorf("ResolveAlias(old) = %q, %v, want TaroYamada2", id, err)
+	}
+}
+
+// renameRecorder: 受け取った user_id の変更イベントを記録する RenameListener
+type renameRecorder struct {
+	events []usecase.UserRenamed
+}
+
+func (r *renameRecorder) UserRenamed(ev usecase.UserRenamed) { r.events = append(r.events, ev) }
+
+func TestRenameUserNotifiesListeners(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	rec := &renameRecorder{}
+	uc.RenameListeners = []usecase.RenameListener{rec}
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	// 変更がない場合・失敗した場合は通知しない
+	if _, err := uc.RenameUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "TaroYamada"); err != nil {
+		t.Fatalf("RenameUser(same id): %v", err)
+	}
+	groups := uc.Groups
+	uc.Groups = failingGroupRepo{groups}
+	if _, err := uc.RenameUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "TaroYamada2"); err == nil {
+		t.Fatal("RenameUser: want error")
+	}
+	if len(rec.events) != 0 {
+		t.Fatalf("events = %+v, want none", rec.events)
+	}
+
+	uc.Groups = groups
+	if _, err := uc.RenameUser("TaroYamada", basic("TaroYamada", "PaSSwd4TY"), "TaroYamada2"); err != nil {
+		t.Fatalf("RenameUser: %v", err)
+	}
+	want := usecase.UserRenamed{Tenant: domain.DefaultTenant, OldID: "TaroYamada", NewID: "TaroYamada2", At: clock.Now()}
+	if len(rec.events) != 1 || rec.events[0] != want {
+		t.Errorf("events = %+v, want %+v", rec.events, want)
+	}
+}
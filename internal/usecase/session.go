@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// DefaultSessionTTL: SessionTTL 未指定時のセッション有効期間
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// Session: 利用者に見せるセッション情報。Token は発行時のみ設定される
+type Session struct {
+	ID         string
+	Token      string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	IP         string
+	UserAgent  string
+	Current    bool // 一覧を取得したリクエスト自身のセッション
+	// ImpersonatorID: 管理者によるなりすましセッションの場合、その管理者の user_id
+	ImpersonatorID string
+}
+
+// CreateSession: user_id/password で認証し、新しいセッションを発行する
+func (u *Usecase) CreateSession(userID, password string, client ClientInfo) (*Session, error) {
+	d, err := u.authenticatePassword(userID, password, client)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newToken(32)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	if err := u.pruneSessions(d.UserID, now); err != nil {
+		return nil, err
+	}
+	rec := &domain.SessionRecord{
+		ID:         id,
+		UserID:     d.UserID,
+		TokenHash:  hashToken(token),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(u.sessionTTL()),
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+	}
+	if err := u.Sessions.Create(rec); err != nil {
+		return nil, err
+	}
+	s := toSession(rec, "")
+	s.Token = token
+	return s, nil
+}
+
+// ListSessions: 認証ユーザーの有効なセッション一覧
+func (u *Usecase) ListSessions(cred Credential) ([]*Session, error) {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return nil, err
+	}
+	now := u.now()
+	if err := u.pruneSessions(p.user.UserID, now); err != nil {
+		return nil, err
+	}
+	recs, err := u.Sessions.ListByUser(p.user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Session, 0, len(recs))
+	for _, rec := range recs {
+		if !now.Before(rec.ExpiresAt) {
+			continue
+		}
+		out = append(out, toSession(rec, p.sessionID))
+	}
+	return out, nil
+}
+
+// RevokeSession: 認証ユーザー自身のセッションを 1 件失効させる
+func (u *Usecase) RevokeSession(cred Credential, sessionID string) error {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return err
+	}
+	if p.impersonating() {
+		return ErrImpersonationForbidden
+	}
+	if err := u.Sessions.Delete(p.user.UserID, sessionID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	u.record(p.user.UserID, domain.ActivitySessionRevoke, domain.ActivitySuccess, cred.Client)
+	return nil
+}
+
+// RevokeAllSessions: 認証ユーザーのセッションをすべて失効させる（すべての端末からサインアウト）
+func (u *Usecase) RevokeAllSessions(cred Credential) error {
+	p, err := u.authenticate(cred)
+	if err != nil {
+		return err
+	}
+	if p.impersonating() {
+		return ErrImpersonationForbidden
+	}
+	if err := u.Sessions.DeleteByUser(p.user.UserID); err != nil {
+		return err
+	}
+	u.record(p.user.UserID, domain.ActivitySessionRevokeAll, domain.ActivitySuccess, cred.Client)
+	return nil
+}
+
+// pruneSessions: userID の期限切れのセッションを削除する。提示されないまま放置されたトークンを溜めないため、
+// セッションの発行・一覧のたびに行う
+func (u *Usecase) pruneSessions(userID string, now time.Time) error {
+	return u.Sessions.DeleteExpired(userID, now)
+}
+
+func (u *Usecase) sessionTTL() time.Duration {
+	if u.SessionTTL > 0 {
+		return u.SessionTTL
+	}
+	return DefaultSessionTTL
+}
+
+func toSession(rec *domain.SessionRecord, currentID string) *Session {
+	return &Session{
+		ID:             rec.ID,
+		CreatedAt:      rec.CreatedAt,
+		LastSeenAt:     rec.LastSeenAt,
+		ExpiresAt:      rec.ExpiresAt,
+		IP:             rec.IP,
+		UserAgent:      rec.UserAgent,
+		Current:        currentID != "" && rec.ID == currentID,
+		ImpersonatorID: rec.ImpersonatorID,
+	}
+}
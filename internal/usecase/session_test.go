@@ -0,0 +1,55 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"accountapi/internal/usecase"
+)
+
+func TestCreateSessionPrunesExpiredSessions(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	uc.SessionTTL = time.Hour
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+
+	// 提示されないまま期限切れになるトークン
+	for range 3 {
+		if _, err := uc.CreateSession("TaroYamada", "PaSSwd4TY", usecase.ClientInfo{}); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+	}
+	clock.Advance(2 * time.Hour)
+	s, err := uc.CreateSession("TaroYamada", "PaSSwd4TY", usecase.ClientInfo{})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	recs, err := uc.Sessions.ListByUser("TaroYamada")
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(recs) != 1 || recs[0].ID != s.ID {
+		t.Errorf("stored sessions = %d, want only the new one", len(recs))
+	}
+}
+
+func TestListSessionsPrunesExpiredSessions(t *testing.T) {
+	uc, clock := newTestUsecase(t)
+	uc.SessionTTL = time.Hour
+	mustSignUp(t, uc, "TaroYamada", "PaSSwd4TY")
+	if _, err := uc.CreateSession("TaroYamada", "PaSSwd4TY", usecase.ClientInfo{}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	clock.Advance(time.Hour)
+
+	list, err := uc.ListSessions(basic("TaroYamada", "PaSSwd4TY"))
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("listed sessions = %d, want 0", len(list))
+	}
+	if recs, _ := uc.Sessions.ListByUser("TaroYamada"); len(recs) != 0 {
+		t.Errorf("stored sessions = %d, want expired ones removed", len(recs))
+	}
+}
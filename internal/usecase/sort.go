@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"accountapi/internal/domain"
+)
+
+// UserSortKey: ユーザー一覧の並び順に使う項目
+type UserSortKey string
+
+const (
+	// UserSortDefault: 一覧ごとの既定の順（フォロー・ブロックした日時）
+	UserSortDefault   UserSortKey = ""
+	UserSortCreatedAt UserSortKey = "created_at"
+	UserSortUpdatedAt UserSortKey = "updated_at"
+)
+
+// UserSort: ユーザー一覧の並び順。最終ログイン日時は本人以外に見せないため並び順にも使わない
+type UserSort struct {
+	Key  UserSortKey
+	Desc bool
+}
+
+// ParseUserSort: "created_at"（昇順）・"-created_at"（降順）の形式。空文字は既定の順
+func ParseUserSort(s string) (UserSort, bool) {
+	var us UserSort
+	s, us.Desc = strings.CutPrefix(s, "-")
+	switch key := UserSortKey(s); key {
+	case UserSortCreatedAt, UserSortUpdatedAt:
+		us.Key = key
+	case UserSortDefault:
+		if us.Desc {
+			return UserSort{}, false
+		}
+	default:
+		return UserSort{}, false
+	}
+	return us, true
+}
+
+func (s UserSort) value(rec *domain.UserRecord) time.Time {
+	if s.Key == UserSortUpdatedAt {
+		return rec.UpdatedAt
+	}
+	return rec.CreatedAt
+}
+
+// sortUsers: users[i] のユーザーの s.Key で items を並べ替える。同じ値の間では元の順を保つ
+func sortUsers[T any](items []T, users []*domain.UserRecord, s UserSort) {
+	if s.Key == UserSortDefault {
+		return
+	}
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		va, vb := s.value(users[idx[a]]), s.value(users[idx[b]])
+		if s.Desc {
+			return va.After(vb)
+		}
+		return va.Before(vb)
+	})
+	sortedItems := make([]T, len(items))
+	sortedUsers := make([]*domain.UserRecord, len(users))
+	for i, j := range idx {
+		sortedItems[i], sortedUsers[i] = items[j], users[j]
+	}
+	copy(items, sortedItems)
+	copy(users, sortedUsers)
+}
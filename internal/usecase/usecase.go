@@ -2,12 +2,71 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"accountapi/internal/domain"
 )
 
 type Usecase struct {
 	Repo domain.UserRepository
+	// Tenant: Repo の名前空間。user_id の一意性・認証はテナントごとで、
+	// Sessions などの他の Repository・Blobs はテナントごとに別のものを渡す
+	Tenant string
+	// ConcealExistingUsers: true の場合、/signup で既存 user_id を指定されても成功時と同じ応答を返す（user_id の列挙対策）
+	ConcealExistingUsers bool
+	// Hashing: bcrypt のハッシュ化・照合を実行する。nil の場合は呼び出し元の goroutine で実行する
+	Hashing Executor
+	// ImageProcessing: アバター画像のデコード・縮小を実行する（メモリを多く使うため同時実行数を抑える）。nil の場合は呼び出し元の goroutine で実行する
+	ImageProcessing Executor
+	Sessions        domain.SessionRepository
+	// SessionTTL: セッションの有効期間（0 は既定値 DefaultSessionTTL）
+	SessionTTL time.Duration
+	Mailer     Mailer
+	// EmailVerificationTTL: メールアドレス確認トークンの有効期間（0 は既定値）
+	EmailVerificationTTL time.Duration
+	// EmailResendInterval: 確認メールを再び送れるまでの間隔（0 は既定値）
+	EmailResendInterval time.Duration
+	Activity            domain.ActivityRepository
+	// Admins: 管理者の user_id（なりすましなどの管理操作を許可する）
+	Admins []string
+	// ImpersonationTTL: なりすましセッションの有効期間（0 は既定値）
+	ImpersonationTTL time.Duration
+	// Blobs: アバター画像の保存先
+	Blobs domain.BlobStore
+	// RejectConfusableNicknames: 他ユーザーの表示名と紛らわしい nickname を拒否する
+	RejectConfusableNicknames bool
+	// UniqueNicknames: nickname の重複を許さない（大文字小文字・紛らわしい文字の違いは同一視）
+	UniqueNicknames bool
+	// ReservedNicknames: 誰も使えない nickname（比較は UniqueNicknames と同じ）
+	ReservedNicknames []string
+	Blocks            domain.BlockRepository
+	Follows           domain.FollowRepository
+	Revisions         domain.ProfileRevisionRepository
+	// Moderator: nickname・comment の内容審査（nil は審査しない）
+	Moderator Moderator
+	// Moderation: 審査待ちのプロフィール変更
+	Moderation domain.ModerationRepository
+	// ProfileSchema: カスタムプロフィール項目の定義（nil は項目なし）
+	ProfileSchema *domain.ProfileSchema
+	// Groups: グループとメンバーシップ
+	Groups domain.GroupRepository
+	// Exports: 個人データの書き出し要求。Archiver でまとめたアーカイブは Blobs に置く
+	Exports  domain.ExportRepository
+	Archiver Archiver
+	// ExportTTL: 書き出したアーカイブをダウンロードできる期間（0 は既定値）
+	ExportTTL time.Duration
+	// UserIDAliasTTL: user_id の変更後、旧 user_id を別名として残す期間（0 は既定値）
+	UserIDAliasTTL time.Duration
+	// RenameListeners: user_id の変更を通知する先
+	RenameListeners []RenameListener
+	// Now: 現在時刻（テストなどで差し替え可能。nil は time.Now）
+	Now func() time.Time
+}
+
+// Executor: CPU 負荷の高い処理の実行を制限する。混雑時は fn を実行せずにエラーを返す
+type Executor interface {
+	Do(fn func()) error
 }
 
 type ValidationReason string
@@ -18,12 +77,36 @@ const (
 	ValidationReasonInvalidPattern       ValidationReason = ValidationReason(domain.ValidationReasonInvalidPattern)
 	ValidationReasonProfileRequired      ValidationReason = ValidationReason(domain.ValidationReasonProfileRequired)
 	ValidationReasonProfileConstraint    ValidationReason = ValidationReason(domain.ValidationReasonProfileConstraint)
+	ValidationReasonEmailInvalid         ValidationReason = ValidationReason(domain.ValidationReasonEmailInvalid)
+	ValidationReasonAvatarTooLarge       ValidationReason = ValidationReason(domain.ValidationReasonAvatarTooLarge)
+	ValidationReasonAvatarFormat         ValidationReason = ValidationReason(domain.ValidationReasonAvatarFormat)
+	ValidationReasonAvatarDimensions     ValidationReason = ValidationReason(domain.ValidationReasonAvatarDimensions)
+	ValidationReasonAttributeUnknown     ValidationReason = ValidationReason(domain.ValidationReasonProfileAttributeUnknown)
+	ValidationReasonAttributeInvalid     ValidationReason = ValidationReason(domain.ValidationReasonProfileAttributeInvalid)
+	ValidationReasonAttributeRequired    ValidationReason = ValidationReason(domain.ValidationReasonProfileAttributeRequired)
+	ValidationReasonPrivacyInvalid       ValidationReason = ValidationReason(domain.ValidationReasonPrivacyInvalid)
 	ValidationReasonUserAlreadyExists    ValidationReason = "user_already_exists"
 	ValidationReasonNotUpdatableIDOrPass ValidationReason = "not_updatable_id_or_password"
+	ValidationReasonEmailAlreadyUsed     ValidationReason = "email_already_used"
+	ValidationReasonEmailTokenInvalid    ValidationReason = "email_token_invalid"
+	ValidationReasonImpersonationReason  ValidationReason = "impersonation_reason_required"
+	ValidationReasonIdenticonSize        ValidationReason = "identicon_size"
+	ValidationReasonNicknameConfusable   ValidationReason = "nickname_confusable"
+	ValidationReasonNicknameTaken        ValidationReason = "nickname_taken"
+	ValidationReasonNicknameReserved     ValidationReason = "nickname_reserved"
+	ValidationReasonContentRejected      ValidationReason = "content_rejected"
+	ValidationReasonBlockSelf            ValidationReason = "block_self"
+	ValidationReasonFollowSelf           ValidationReason = "follow_self"
+	ValidationReasonCursorInvalid        ValidationReason = "cursor_invalid"
+	ValidationReasonGroupName            ValidationReason = ValidationReason(domain.ValidationReasonGroupName)
+	ValidationReasonGroupRole            ValidationReason = "group_role"
+	ValidationReasonGroupOwnerRequired   ValidationReason = "group_owner_required"
 )
 
 type ValidationError struct {
 	Reason ValidationReason
+	// Fields: 失敗したすべての項目（項目ごとに検証する入力のみ）
+	Fields []domain.FieldError
 }
 
 func (e *ValidationError) Error() string { return string(e.Reason) }
@@ -32,6 +115,11 @@ var (
 	ErrAuthFailed = errors.New("auth failed") // 401
 	ErrNoPerm     = errors.New("no perm")     // 403
 	ErrNotFound   = errors.New("not found")   // 404
+	ErrBusy       = errors.New("busy")        // 503
+	// ErrImpersonationForbidden: なりすまし中は許可しない操作（ErrNoPerm として扱える）
+	ErrImpersonationForbidden = fmt.Errorf("%w: not allowed while impersonating", ErrNoPerm)
+	// ErrDeactivated: 利用停止中のアカウントは変更できない（ErrNoPerm として扱える）
+	ErrDeactivated = fmt.Errorf("%w: account is deactivated", ErrNoPerm)
 )
 
 // SignUp: 既存チェック、ハッシュ化、作成
@@ -40,111 +128,264 @@ func (u *Usecase) SignUp(userID, rawPassword string) (*domain.User, error) {
 	if err != nil {
 		return nil, mapValidationError(err)
 	}
-	if err := user.HashPassword(rawPassword); err != nil {
+	var hashErr error
+	if err := u.hash(func() { hashErr = user.HashPassword(rawPassword) }); err != nil {
 		return nil, err
 	}
+	if hashErr != nil {
+		return nil, hashErr
+	}
+	now := u.now()
 	rec := &domain.UserRecord{
+		Tenant:       u.Tenant,
 		UserID:       user.UserID,
 		PasswordHash: user.PasswordHash,
 		Nickname:     "",
 		Comment:      "",
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
-	if err := u.Repo.Create(rec); err != nil {
+	// 管理者の user_id は設定で用意したアカウント（ProvisionAdmin）のみ。
+	// 未登録・退会済みでもサインアップでは取得できず、既存の user_id と同じ応答にする
+	if u.isAdmin(rec.UserID) {
+		err = domain.ErrAlreadyExists
+	} else {
+		err = u.Repo.Create(rec)
+	}
+	if err != nil {
 		if errors.Is(err, domain.ErrAlreadyExists) {
+			if u.ConcealExistingUsers {
+				// ハッシュ化は作成前に済んでいるため、応答内容・時間とも新規作成と区別できない
+				return &domain.User{UserID: user.UserID}, nil
+			}
 			return nil, &ValidationError{Reason: ValidationReasonUserAlreadyExists}
 		}
 		return nil, err
 	}
+	user.CreatedAt, user.UpdatedAt = now, now
 	return user, nil
 }
 
-// GetUser: Basic 認証（userID/pw）を検証して本人の情報を返す
-func (u *Usecase) GetUser(pathUserID, authUserID, authPassword string) (*domain.User, error) {
+// ProvisionAdmin: 管理者のアカウントを設定のパスワードハッシュ（bcrypt）で用意する（起動時に呼び出す）。
+// 管理者の user_id はサインアップ・user_id の変更では取得できないため、ここでのみ作成する。既に存在すれば何もしない
+func (u *Usecase) ProvisionAdmin(userID, passwordHash string) error {
+	if !u.isAdmin(userID) {
+		return fmt.Errorf("%s is not an admin", userID)
+	}
+	if err := domain.ValidateUserID(userID); err != nil {
+		return fmt.Errorf("admin %s: invalid user_id: %w", userID, err)
+	}
+	if !domain.ValidPasswordHash(passwordHash) {
+		return fmt.Errorf("admin %s: invalid password hash", userID)
+	}
+	now := u.now()
+	err := u.Repo.Create(&domain.UserRecord{
+		Tenant:       u.Tenant,
+		UserID:       userID,
+		PasswordHash: passwordHash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if err != nil && !errors.Is(err, domain.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// UserView: 閲覧者から見たユーザー情報
+type UserView struct {
+	*domain.User
+	Self bool // 閲覧者本人
+	// フォロワー数・フォロー数（一覧と同じく閲覧者から見えるユーザーのみ）
+	Followers int
+	Following int
+}
+
+// GetUser: 認証（Basic またはセッション）を検証して本人または指定ユーザーの情報を返す
+func (u *Usecase) GetUser(pathUserID string, cred Credential) (*UserView, error) {
 	// 認証ユーザーの存在確認とパスワード検証
-	authRec, err := u.Repo.FindByID(authUserID)
+	p, err := u.authenticate(cred)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return nil, ErrAuthFailed
-		}
 		return nil, err
 	}
-	authUser := toDomain(authRec)
-	if !authUser.VerifyPassword(authPassword) {
-		return nil, ErrAuthFailed
-	}
 
 	// 自身の場合はそのまま返す
-	if pathUserID == authUserID {
-		return authUser, nil
+	view := &UserView{User: p.user, Self: true}
+	if pathUserID != p.user.UserID {
+		// 別ユーザーの取得。ブロックされている・非公開の場合は存在を明かさない
+		targetRec, err := u.findVisible(pathUserID, p.user.UserID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				// 変更前の user_id なら変更後の user_id を MovedError で知らせる
+				if aErr := u.resolveAlias(pathUserID, p.user.UserID); !errors.Is(aErr, ErrNotFound) {
+					return nil, aErr
+				}
+			}
+			return nil, err
+		}
+		viewer, err := u.viewerOf(targetRec.UserID, p.user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		view = &UserView{User: u.publicView(toDomain(targetRec), viewer)}
 	}
+	if view.Followers, view.Following, err = u.followCounts(view.UserID, p.user.UserID); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
 
-	// 別ユーザーの取得
-	targetRec, err := u.Repo.FindByID(pathUserID)
-	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return nil, ErrNotFound
+// publicView: 本人以外の閲覧者に見せてよい項目だけを残す（プロフィール全体の公開範囲は呼び出し元で確認済み）
+func (u *Usecase) publicView(d *domain.User, viewer domain.Viewer) *domain.User {
+	privacy := d.Privacy
+	if !privacy.FieldVisibility(domain.PrivacyFieldNickname, domain.VisibilityPublic).VisibleTo(viewer) {
+		// 表示名は user_id になる
+		d.Nickname = ""
+	}
+	if !privacy.FieldVisibility(domain.PrivacyFieldComment, domain.VisibilityPublic).VisibleTo(viewer) {
+		d.Comment = ""
+	}
+	var attrs map[string]string
+	for name, v := range d.Attributes {
+		f := u.ProfileSchema.Field(name)
+		// スキーマから外れた項目は返さない
+		if f == nil || !privacy.FieldVisibility(name, f.Visibility).VisibleTo(viewer) {
+			continue
 		}
-		return nil, err
+		if attrs == nil {
+			attrs = make(map[string]string, len(d.Attributes))
+		}
+		attrs[name] = v
 	}
-	return toDomain(targetRec), nil
+	d.Attributes = attrs
+	return d
 }
 
 // UpdateUser: 本人認証し、プロフィールのみ更新
-func (u *Usecase) UpdateUser(pathUserID, authUserID, authPassword string, nickname *string, comment *string, forbidChangingIDOrPass bool) (*domain.User, error) {
-	if pathUserID != authUserID {
-		return nil, ErrNoPerm // 403
-	}
-	rec, err := u.Repo.FindByID(authUserID)
+func (u *Usecase) UpdateUser(pathUserID string, cred Credential, upd domain.ProfileUpdate, forbidChangingIDOrPass bool) (*domain.User, error) {
+	p, err := u.authenticateActiveOwner(pathUserID, cred)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return nil, ErrNotFound
-		}
 		return nil, err
 	}
-	d := toDomain(rec)
-	if !d.VerifyPassword(authPassword) {
-		return nil, ErrAuthFailed
-	}
 	// user_id/password がボディに含まれていたら即 400
 	if forbidChangingIDOrPass {
 		return nil, &ValidationError{Reason: ValidationReasonNotUpdatableIDOrPass}
 	}
-	if err := d.ApplyProfileUpdate(nickname, comment); err != nil {
+	return u.updateProfile(p, upd, 0, cred.Client)
+}
+
+// updateProfile: 審査・検証を経てプロフィールを更新し、変更履歴に残す（revertOf は過去の版を戻す場合のみ）
+func (u *Usecase) updateProfile(p *principal, upd domain.ProfileUpdate, revertOf uint64, client ClientInfo) (*domain.User, error) {
+	d := p.user
+	upd, held, matches, err := u.moderate(upd)
+	if err != nil {
+		return nil, err
+	}
+	rev := &domain.ProfileRevision{UserID: d.UserID, ActorID: p.actor(), Old: d.Snapshot(), RevertOf: revertOf}
+	if err := d.ApplyProfileUpdate(upd, u.ProfileSchema); err != nil {
 		return nil, mapValidationError(err)
 	}
-	if err := u.Repo.UpdateProfile(d.UserID, d.Nickname, d.Comment); err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return nil, ErrNotFound
+	if held {
+		if err := u.holdProfileUpdate(d, upd, matches); err != nil {
+			return nil, err
 		}
+		return nil, ErrPendingReview
+	}
+	if err := u.saveProfile(d, upd, rev); err != nil {
 		return nil, err
 	}
+	u.recordFor(p, domain.ActivityProfileUpdate, domain.ActivitySuccess, client)
 	return d, nil
 }
 
-// CloseUser: 本人認証し、物理削除（未存在も 401）
-func (u *Usecase) CloseUser(authUserID, authPassword string) error {
-	rec, err := u.Repo.FindByID(authUserID)
+// saveProfile: ApplyProfileUpdate 済みのプロフィールを nickname の制約とともに保存し、
+// 値が変わっていれば rev（Old・ActorID は設定済み）を変更履歴に追加する
+func (u *Usecase) saveProfile(d *domain.User, upd domain.ProfileUpdate, rev *domain.ProfileRevision) error {
+	if upd.Nickname != nil && u.nicknameReserved(d.Nickname) {
+		return &ValidationError{Reason: ValidationReasonNicknameReserved}
+	}
+	profile := domain.Profile{
+		Nickname:       d.Nickname,
+		Comment:        d.Comment,
+		Attributes:     d.Attributes,
+		NicknamePolicy: u.nicknamePolicy(),
+	}
+	now := u.now()
+	if err := u.Repo.UpdateProfile(u.Tenant, d.UserID, profile, now); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrNotFound
+		}
+		if reason, ok := nicknameConflictReason(err); ok {
+			return &ValidationError{Reason: reason}
+		}
+		return err
+	}
+	d.UpdatedAt = now
+	rev.New = d.Snapshot()
+	if rev.New.Equal(rev.Old) {
+		return nil
+	}
+	rev.At = now
+	return u.Revisions.Append(rev)
+}
+
+// CloseUser: 本人認証し、物理削除（未存在も 401）。セッション・利用履歴・アバター・審査待ちの変更・ブロック・フォロー・変更履歴・書き出しもすべて破棄し、グループから外す
+func (u *Usecase) CloseUser(cred Credential) error {
+	p, err := u.authenticate(cred)
 	if err != nil {
+		if errors.Is(err, ErrBusy) {
+			return err
+		}
 		// /close は未存在も 401
 		return ErrAuthFailed
 	}
-	d := toDomain(rec)
-	if !d.VerifyPassword(authPassword) {
-		return ErrAuthFailed
+	if p.impersonating() {
+		return ErrImpersonationForbidden
 	}
-	if err := u.Repo.Delete(d.UserID); err != nil {
+	if err := u.Repo.Delete(u.Tenant, p.user.UserID); err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			return ErrAuthFailed
 		}
 		return err
 	}
-	return nil
+	if err := u.Sessions.DeleteByUser(p.user.UserID); err != nil {
+		return err
+	}
+	if err := u.Activity.DeleteByUser(p.user.UserID); err != nil {
+		return err
+	}
+	if err := u.Moderation.DeleteByUser(p.user.UserID); err != nil {
+		return err
+	}
+	if err := u.Blocks.DeleteByUser(p.user.UserID); err != nil {
+		return err
+	}
+	if err := u.Follows.DeleteByUser(p.user.UserID); err != nil {
+		return err
+	}
+	if err := u.Revisions.DeleteByUser(p.user.UserID); err != nil {
+		return err
+	}
+	if err := u.deleteExports(p.user.UserID); err != nil {
+		return err
+	}
+	if err := u.leaveGroups(p.user.UserID); err != nil {
+		return err
+	}
+	return u.Blobs.DeletePrefix(avatarPrefix(p.user.UserID, ""))
+}
+
+func (u *Usecase) now() time.Time {
+	if u.Now != nil {
+		return u.Now()
+	}
+	return time.Now()
 }
 
 func mapValidationError(err error) error {
 	var vErr *domain.ErrValidation
 	if errors.As(err, &vErr) {
-		return &ValidationError{Reason: validationReasonFromDomain(vErr.Reason)}
+		return &ValidationError{Reason: validationReasonFromDomain(vErr.Reason), Fields: vErr.Fields}
 	}
 	return err
 }
@@ -161,6 +402,22 @@ func validationReasonFromDomain(reason domain.ValidationReason) ValidationReason
 		return ValidationReasonProfileRequired
 	case domain.ValidationReasonProfileConstraint:
 		return ValidationReasonProfileConstraint
+	case domain.ValidationReasonEmailInvalid:
+		return ValidationReasonEmailInvalid
+	case domain.ValidationReasonAvatarTooLarge:
+		return ValidationReasonAvatarTooLarge
+	case domain.ValidationReasonAvatarFormat:
+		return ValidationReasonAvatarFormat
+	case domain.ValidationReasonAvatarDimensions:
+		return ValidationReasonAvatarDimensions
+	case domain.ValidationReasonProfileAttributeUnknown:
+		return ValidationReasonAttributeUnknown
+	case domain.ValidationReasonProfileAttributeInvalid:
+		return ValidationReasonAttributeInvalid
+	case domain.ValidationReasonProfileAttributeRequired:
+		return ValidationReasonAttributeRequired
+	case domain.ValidationReasonPrivacyInvalid:
+		return ValidationReasonPrivacyInvalid
 	default:
 		return ValidationReason(reason)
 	}
@@ -168,10 +425,20 @@ func validationReasonFromDomain(reason domain.ValidationReason) ValidationReason
 
 func toDomain(rec *domain.UserRecord) *domain.User {
 	return &domain.User{
-		UserID:       rec.UserID,
-		PasswordHash: rec.PasswordHash,
-		Nickname:     rec.Nickname,
-		Comment:      rec.Comment,
-		Deleted:      rec.Deleted,
+		Tenant:        rec.Tenant,
+		UserID:        rec.UserID,
+		PasswordHash:  rec.PasswordHash,
+		Nickname:      rec.Nickname,
+		Comment:       rec.Comment,
+		Deleted:       rec.Deleted,
+		Email:         rec.Email,
+		EmailVerified: rec.EmailVerified,
+		AvatarID:      rec.AvatarID,
+		Attributes:    rec.Attributes,
+		Privacy:       rec.Privacy,
+		CreatedAt:     rec.CreatedAt,
+		UpdatedAt:     rec.UpdatedAt,
+		LastLoginAt:   rec.LastLoginAt,
+		DeactivatedAt: rec.DeactivatedAt,
 	}
 }
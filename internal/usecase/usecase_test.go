@@ -0,0 +1,53 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"accountapi/internal/infrastructure/blobstore/localfs"
+	"accountapi/internal/infrastructure/export"
+	"accountapi/internal/infrastructure/mailer"
+	"accountapi/internal/infrastructure/repository/memrepo"
+	"accountapi/internal/usecase"
+)
+
+// newTestUsecase: メモリ上のストアだけで動く Usecase（時刻は clock で進める）
+func newTestUsecase(t *testing.T) (*usecase.Usecase, *testClock) {
+	t.Helper()
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	uc := &usecase.Usecase{
+		Repo:       memrepo.New(),
+		Sessions:   memrepo.NewSessionRepo(),
+		Mailer:     mailer.NewSink(),
+		Activity:   memrepo.NewActivityRepo(0, 0),
+		Blobs:      localfs.New(t.TempDir()),
+		Moderation: memrepo.NewModerationRepo(),
+		Blocks:     memrepo.NewBlockRepo(),
+		Follows:    memrepo.NewFollowRepo(),
+		Revisions:  memrepo.NewRevisionRepo(),
+		Groups:     memrepo.NewGroupRepo(),
+		Exports:    memrepo.NewExportRepo(),
+		Archiver:   export.NewZipArchiver(),
+		Now:        clock.Now,
+	}
+	return uc, clock
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func mustSignUp(t *testing.T, uc *usecase.Usecase, userID, password string) {
+	t.Helper()
+	if _, err := uc.SignUp(userID, password); err != nil {
+		t.Fatalf("SignUp(%q): %v", userID, err)
+	}
+}
+
+func basic(userID, password string) usecase.Credential {
+	return usecase.Credential{UserID: userID, Password: password}
+}